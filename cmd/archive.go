@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cpoulin/claude-swarm/internal/git"
+	"github.com/cpoulin/claude-swarm/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// archivePrefix is the ref namespace holding archived worker branches:
+// refs/swarm-archive/<session>/<stamp>/worker-N.
+const archivePrefix = "refs/swarm-archive/"
+
+// archiveStamp is the time layout of the <stamp> path segment.
+const archiveStamp = "2006-01-02-150405"
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "List, restore or prune archived worker branches",
+	Long: `Before cleanup deletes a worker branch, its tip — plus any uncommitted
+changes, saved as a snapshot commit on top — is kept under
+refs/swarm-archive/<session>/<date>/worker-N. Branches the worker left for
+queued tasks are kept next to it as worker-N-task-M.`,
+}
+
+func init() {
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List archived workers (all sessions unless -s is given)",
+		RunE:  runArchiveList,
+	}
+	restoreCmd := &cobra.Command{
+		Use:   "restore <session/date/worker-N>",
+		Short: "Recreate a branch (and optionally a worktree) from an archive",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runArchiveRestore,
+	}
+	restoreCmd.Flags().String("branch", "", "Branch to create (default: restored/<session>/<date>/worker-N)")
+	restoreCmd.Flags().String("worktree", "", "Also check the branch out here, with uncommitted changes re-applied")
+	pruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete archives older than a given age",
+		RunE:  runArchivePrune,
+	}
+	pruneCmd.Flags().String("older-than", "30d", "Delete archives older than this (e.g. 12h, 7d)")
+	pruneCmd.Flags().Bool("dry-run", false, "Only print what would be deleted")
+
+	archiveCmd.AddCommand(listCmd, restoreCmd, pruneCmd)
+	rootCmd.AddCommand(archiveCmd)
+}
+
+// archiveWorker saves a worker's branch tip and uncommitted changes under the
+// archive namespace and returns the created ref.
+func archiveWorker(session string, wk state.Worker, now time.Time) (string, error) {
+	label := fmt.Sprintf("worker-%d", wk.Index)
+	var sha string
+	var err error
+	if _, statErr := os.Stat(wk.Worktree); statErr == nil {
+		sha, err = git.SnapshotWorktree(wk.Worktree, label)
+	} else {
+		sha, err = git.RevParse(wk.Branch)
+	}
+	if err != nil {
+		return "", fmt.Errorf("archiving %s: %w", label, err)
+	}
+	return saveArchive(session, label, sha, wk.Branch, now)
+}
+
+// archiveBranch saves the tip of a branch the worker no longer has checked
+// out under the archive namespace and returns the created ref.
+func archiveBranch(session, label, branch string, now time.Time) (string, error) {
+	sha, err := git.RevParse(branch)
+	if err != nil {
+		return "", fmt.Errorf("archiving %s: %w", label, err)
+	}
+	return saveArchive(session, label, sha, branch, now)
+}
+
+func saveArchive(session, label, sha, branch string, now time.Time) (string, error) {
+	ref := fmt.Sprintf("%s%s/%s/%s", archivePrefix, session, now.Format(archiveStamp), label)
+	if err := git.UpdateRef(ref, sha, "claude-swarm archive "+branch); err != nil {
+		return "", err
+	}
+	return ref, nil
+}
+
+// pastLabel names the archive of one of a worker's past branches after the
+// worker and the branch's last path segment: worker-1-task-3.
+func pastLabel(wk state.Worker, branch string) string {
+	label := fmt.Sprintf("worker-%d", wk.Index)
+	if name := path.Base(branch); name != label {
+		return label + "-" + name
+	}
+	return label + "-start"
+}
+
+// archiveEntry is a parsed archive ref.
+type archiveEntry struct {
+	git.Ref
+	Session string
+	Time    time.Time
+	Worker  string
+}
+
+func (e archiveEntry) short() string {
+	return strings.TrimPrefix(e.Name, archivePrefix)
+}
+
+func (e archiveEntry) snapshot() bool {
+	return strings.HasPrefix(e.Subject, git.SnapshotSubject)
+}
+
+func listArchives(session string) ([]archiveEntry, error) {
+	prefix := archivePrefix
+	if session != "" {
+		prefix += session + "/"
+	}
+	refs, err := git.ListRefs(prefix)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]archiveEntry, 0, len(refs))
+	for _, r := range refs {
+		parts := strings.Split(strings.TrimPrefix(r.Name, archivePrefix), "/")
+		if len(parts) < 3 {
+			continue
+		}
+		n := len(parts)
+		t, _ := time.ParseInLocation(archiveStamp, parts[n-2], time.Local)
+		entries = append(entries, archiveEntry{
+			Ref:     r,
+			Session: strings.Join(parts[:n-2], "/"),
+			Time:    t,
+			Worker:  parts[n-1],
+		})
+	}
+	return entries, nil
+}
+
+func runArchiveList(cmd *cobra.Command, args []string) error {
+	session := ""
+	if cmd.Flags().Changed("session") {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		session = cfg.Session
+	}
+	entries, err := listArchives(session)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("ℹ️   No archived workers.")
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ARCHIVE\tCOMMIT\tUNCOMMITTED\tSUBJECT")
+	for _, e := range entries {
+		wip := "no"
+		if e.snapshot() {
+			wip = "yes"
+		}
+		fmt.Fprintf(tw, "%s\t%.8s\t%s\t%s\n", e.short(), e.SHA, wip, e.Subject)
+	}
+	return tw.Flush()
+}
+
+func runArchiveRestore(cmd *cobra.Command, args []string) error {
+	branch, _ := cmd.Flags().GetString("branch")
+	worktree, _ := cmd.Flags().GetString("worktree")
+
+	name := strings.TrimPrefix(args[0], archivePrefix)
+	entries, err := listArchives("")
+	if err != nil {
+		return err
+	}
+	var entry *archiveEntry
+	for i := range entries {
+		if entries[i].short() == name {
+			entry = &entries[i]
+		}
+	}
+	if entry == nil {
+		return fmt.Errorf("no archive %q — see claude-swarm archive list", name)
+	}
+	if branch == "" {
+		branch = "restored/" + name
+	}
+
+	// Without a worktree the snapshot commit becomes part of the branch; with
+	// one, the branch ends at the original tip and the changes are re-applied
+	// uncommitted, just as the worker left them.
+	tip := entry.SHA
+	if worktree != "" && entry.snapshot() {
+		tip = entry.SHA + "^"
+	}
+	if err := git.CreateBranch(branch, tip); err != nil {
+		return err
+	}
+	fmt.Printf("✅  Branch %s → %.8s\n", branch, entry.SHA)
+
+	if worktree == "" {
+		return nil
+	}
+	if err := git.CheckoutWorktree(worktree, branch); err != nil {
+		return err
+	}
+	if entry.snapshot() {
+		if err := git.ApplyDiff(worktree, entry.SHA+"^", entry.SHA); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("✅  Worktree %s\n", worktree)
+	return nil
+}
+
+func runArchivePrune(cmd *cobra.Command, args []string) error {
+	olderThan, _ := cmd.Flags().GetString("older-than")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	age, err := parseAge(olderThan)
+	if err != nil {
+		return err
+	}
+	session := ""
+	if cmd.Flags().Changed("session") {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		session = cfg.Session
+	}
+	entries, err := listArchives(session)
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-age)
+	pruned := 0
+	for _, e := range entries {
+		if e.Time.IsZero() || e.Time.After(cutoff) {
+			continue
+		}
+		if dryRun {
+			fmt.Printf("would delete %s\n", e.short())
+		} else {
+			if err := git.DeleteRef(e.Name); err != nil {
+				return err
+			}
+			fmt.Printf("🗑️   Deleted %s\n", e.short())
+		}
+		pruned++
+	}
+	fmt.Printf("✅  %d archive(s) older than %s.\n", pruned, olderThan)
+	return nil
+}
+
+// parseAge parses a Go duration, additionally accepting whole days ("7d").
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %q: %w", s, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/cpoulin/claude-swarm/internal/config"
+	"github.com/cpoulin/claude-swarm/internal/git"
+	"github.com/cpoulin/claude-swarm/internal/layout"
+	"github.com/cpoulin/claude-swarm/internal/state"
+	"github.com/cpoulin/claude-swarm/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var attachCmd = &cobra.Command{
+	Use:   "attach",
+	Short: "Re-attach to a running swarm and restart its usage-limit monitors",
+	Long: `Reconnects to a running swarm session without touching its panes or worktrees.
+Workers are read from the session manifest; panes that moved (or a missing
+manifest) are recovered from the "worker-N (cli)" pane titles.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		return runAttach(cfg)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(attachCmd)
+}
+
+func runAttach(cfg *config.Config) error {
+	if !tmux.HasSession(cfg.Session) {
+		return fmt.Errorf("session %q is not running — start it with claude-swarm", cfg.Session)
+	}
+	repoRoot, err := git.MainRoot()
+	if err != nil {
+		return fmt.Errorf("not inside a git repository")
+	}
+
+	unlock, err := lockSession(cfg.Session)
+	if err != nil {
+		return err
+	}
+	m, err := reconcileManifest(cfg, repoRoot)
+	if err == nil && len(m.Workers) == 0 {
+		err = fmt.Errorf("no workers found in session %q", cfg.Session)
+	}
+	if err == nil {
+		if err = state.Save(m); err != nil {
+			err = fmt.Errorf("saving session manifest: %w", err)
+		}
+	}
+	unlock()
+	if err != nil {
+		return err
+	}
+
+	w, closeLog := openLog(cfg.Session)
+	defer closeLog()
+
+	fmt.Printf("📺  Session : %s  (%d workers)\n", cfg.Session, len(m.Workers))
+	return runAndMonitor(cfg, w)
+}
+
+var workerTitleRe = regexp.MustCompile(`^worker-(\d+)(?: \(([^)]+)\))?`)
+
+// titleCLI returns the CLI of a worker recovered from its pane title: the one
+// in the title, else the first of cli_type, else claude.
+func titleCLI(fromTitle, cliType string) string {
+	if fromTitle != "" {
+		return fromTitle
+	}
+	if types := parseCLITypes(cliType); len(types) > 0 {
+		return types[0]
+	}
+	return "claude"
+}
+
+// reconcileManifest loads the session manifest and refreshes each worker's
+// pane ID from the live tmux panes. Without a manifest, workers are rebuilt
+// from the pane titles and working directories of the swarm windows.
+func reconcileManifest(cfg *config.Config, repoRoot string) (*state.Manifest, error) {
+	panes, err := tmux.ListSessionPanes(cfg.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := state.Load(cfg.Session)
+	if errors.Is(err, os.ErrNotExist) {
+		m = &state.Manifest{Session: cfg.Session, RepoRoot: repoRoot, CreatedAt: time.Now().UTC()}
+	} else if err != nil {
+		return nil, err
+	}
+
+	base := cfg.BaseBranch
+	if base == "" {
+		base, _ = git.CurrentBranch()
+	}
+
+	live := make(map[string]bool, len(panes))
+	for _, p := range panes {
+		live[p.ID] = true
+	}
+
+	for _, p := range panes {
+		if _, ok := layout.WindowNumber(p.Window); !ok {
+			continue
+		}
+		match := workerTitleRe.FindStringSubmatch(p.Title)
+		if match == nil {
+			continue
+		}
+		idx, _ := strconv.Atoi(match[1])
+		if wk := m.Worker(idx); wk != nil {
+			if !live[wk.PaneID] {
+				wk.PaneID = p.ID
+			}
+			continue
+		}
+
+		cliName, model := parseWorker(titleCLI(match[2], cfg.CLIType))
+		branch, _ := git.BranchOfWorktree(p.Path)
+		m.Workers = append(m.Workers, state.Worker{
+			Index:      idx,
+			CLI:        cliName,
+			Model:      model,
+			Flags:      cfg.CLIFlags,
+			PaneID:     p.ID,
+			Worktree:   p.Path,
+			Branch:     branch,
+			BaseBranch: base,
+		})
+		fmt.Printf("🔎  Recovered worker %d from pane %s (%s)\n", idx, p.ID, p.Path)
+	}
+	return m, nil
+}
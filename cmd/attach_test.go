@@ -0,0 +1,20 @@
+package cmd
+
+import "testing"
+
+func TestTitleCLI(t *testing.T) {
+	tests := []struct {
+		title, cliType, want string
+	}{
+		{"codex:gpt-5", "claude", "codex:gpt-5"},
+		{"", "gemini,codex", "gemini"},
+		{"", " , codex", "codex"},
+		{"", "", "claude"},
+		{"", ",,", "claude"},
+	}
+	for _, tt := range tests {
+		if got := titleCLI(tt.title, tt.cliType); got != tt.want {
+			t.Errorf("titleCLI(%q, %q) = %q, want %q", tt.title, tt.cliType, got, tt.want)
+		}
+	}
+}
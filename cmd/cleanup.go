@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cpoulin/claude-swarm/internal/config"
+
+	"github.com/cpoulin/claude-swarm/internal/git"
+	"github.com/cpoulin/claude-swarm/internal/state"
+)
+
+// workState classifies how much unsaved work a worker's worktree and branch hold.
+// Values are ordered from safest to riskiest.
+type workState int
+
+const (
+	workClean    workState = iota // nothing beyond the base branch
+	workUnmerged                  // commits not on base, all pushed
+	workUnpushed                  // commits on no remote
+	workDirty                     // uncommitted changes in the worktree
+)
+
+func (s workState) String() string {
+	switch s {
+	case workClean:
+		return "clean"
+	case workUnmerged:
+		return "unmerged"
+	case workUnpushed:
+		return "unpushed"
+	default:
+		return "dirty"
+	}
+}
+
+// workerCheck is the cleanup classification of one worker.
+type workerCheck struct {
+	Worker   state.Worker
+	Ahead    int
+	Unpushed int
+	Dirty    int
+	State    workState
+}
+
+// checkWorker classifies a worker's branch and worktree. Anything it cannot
+// inspect is reported as dirty so it is never removed silently.
+func checkWorker(wk state.Worker) workerCheck {
+	c := workerCheck{Worker: wk}
+	base := wk.BaseBranch
+	if _, err := git.RevParse(base); err != nil {
+		base = wk.BaseCommit
+	}
+
+	var errs []error
+	var err error
+	if _, statErr := os.Stat(wk.Worktree); statErr == nil {
+		c.Dirty, err = git.DirtyFiles(wk.Worktree)
+		errs = append(errs, err)
+	}
+	for _, branch := range wk.Branches() {
+		if _, revErr := git.RevParse(branch); revErr != nil {
+			continue
+		}
+		n, err := git.CountCommits(branch, base)
+		c.Ahead += n
+		errs = append(errs, err)
+		n, err = git.UnpushedCommits(branch, base)
+		c.Unpushed += n
+		errs = append(errs, err)
+	}
+
+	switch {
+	case c.Dirty > 0:
+		c.State = workDirty
+	case c.Unpushed > 0:
+		c.State = workUnpushed
+	case c.Ahead > 0:
+		c.State = workUnmerged
+	}
+	for _, e := range errs {
+		if e != nil {
+			c.State = workDirty
+		}
+	}
+	return c
+}
+
+func printCheckTable(checks []workerCheck) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  WORKER\tBRANCH\tAHEAD\tUNPUSHED\tDIRTY\tSTATE")
+	for _, c := range checks {
+		fmt.Fprintf(tw, "  %d\t%s\t%d\t%d\t%d\t%s\n",
+			c.Worker.Index, c.Worker.Branch, c.Ahead, c.Unpushed, c.Dirty, c.State)
+	}
+	_ = tw.Flush()
+}
+
+// removeWorker deletes a worker's worktree and branches, archiving them first
+// when enabled. Nothing is deleted if the archive cannot be written.
+func removeWorker(cfg *config.Config, session string, wk state.Worker, now time.Time) error {
+	if cfg.Archive {
+		ref, err := archiveWorker(session, wk, now)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("📦  Archived worker-%d → %s\n", wk.Index, strings.TrimPrefix(ref, archivePrefix))
+		for _, branch := range wk.PastBranches {
+			if _, err := git.RevParse(branch); err != nil {
+				continue
+			}
+			if n, err := git.CountCommits(branch, wk.BaseBranch); err == nil && n == 0 {
+				continue // nothing beyond the base branch
+			}
+			ref, err := archiveBranch(session, pastLabel(wk, branch), branch, now)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("📦  Archived %s → %s\n", branch, strings.TrimPrefix(ref, archivePrefix))
+		}
+	}
+	_ = git.RemoveWorktree(wk.Worktree)
+	for _, branch := range wk.Branches() {
+		_ = git.DeleteBranch(branch)
+	}
+	return nil
+}
+
+// cleanupWorkers removes clean workers automatically and asks before removing
+// each risky one, unless --force is set. It returns the indices it removed.
+func cleanupWorkers(cfg *config.Config, session string, checks []workerCheck, stdin *bufio.Reader) []int {
+	now := time.Now()
+	var removed []int
+	for _, c := range checks {
+		if c.State != workClean && !cfg.Force {
+			if !isTerminal() {
+				fmt.Printf("ℹ️   Kept worker-%d (%s; no terminal to confirm — use --yes): %s\n",
+					c.Worker.Index, c.State, c.Worker.Worktree)
+				continue
+			}
+			fmt.Printf("⚠️   worker-%d is %s (%d ahead, %d unpushed, %d dirty). Remove anyway? [y/N] ",
+				c.Worker.Index, c.State, c.Ahead, c.Unpushed, c.Dirty)
+			answer, _ := stdin.ReadString('\n')
+			if !strings.EqualFold(strings.TrimSpace(answer), "y") {
+				fmt.Printf("ℹ️   Kept worker-%d: %s\n", c.Worker.Index, c.Worker.Worktree)
+				continue
+			}
+		}
+		if err := removeWorker(cfg, session, c.Worker, now); err != nil {
+			fmt.Printf("⚠️   Kept worker-%d: %v\n", c.Worker.Index, err)
+			continue
+		}
+		removed = append(removed, c.Worker.Index)
+	}
+	_ = git.Prune()
+	return removed
+}
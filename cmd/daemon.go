@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/cpoulin/claude-swarm/internal/config"
+	"github.com/cpoulin/claude-swarm/internal/monitor"
+	"github.com/cpoulin/claude-swarm/internal/state"
+	"github.com/cpoulin/claude-swarm/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run usage-limit monitors in the background, independent of any attached client",
+	Long: `The daemon watches every worker pane of a session and auto-resumes after
+usage limits, whether or not a terminal is attached. Workers added later
+(claude-swarm -a) are picked up automatically. It exits with the session.`,
+}
+
+func init() {
+	daemonCmd.AddCommand(
+		&cobra.Command{
+			Use:   "start",
+			Short: "Start the monitor daemon for a session",
+			RunE:  withConfig(startDaemon),
+		},
+		&cobra.Command{
+			Use:   "stop",
+			Short: "Stop the monitor daemon for a session",
+			RunE:  withConfig(stopDaemon),
+		},
+		&cobra.Command{
+			Use:   "status",
+			Short: "Show whether the monitor daemon is running",
+			RunE: withConfig(func(cfg *config.Config) error {
+				if pid, ok := state.DaemonPid(cfg.Session); ok {
+					fmt.Printf("🟢  Daemon running for %q (pid %d, log: %s)\n", cfg.Session, pid, logPath(cfg.Session))
+				} else {
+					fmt.Printf("⚪  No daemon running for %q\n", cfg.Session)
+				}
+				return nil
+			}),
+		},
+		&cobra.Command{
+			Use:    "run",
+			Short:  "Run the monitor daemon in the foreground",
+			Hidden: true,
+			RunE:   withConfig(runDaemon),
+		},
+	)
+	rootCmd.AddCommand(daemonCmd)
+}
+
+// withConfig adapts a config-driven function to a cobra RunE.
+func withConfig(fn func(cfg *config.Config) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		return fn(cfg)
+	}
+}
+
+// startDaemon re-executes claude-swarm as a detached "daemon run" process
+// writing to the session log, and waits for it to record its pidfile.
+func startDaemon(cfg *config.Config) error {
+	if pid, ok := state.DaemonPid(cfg.Session); ok {
+		fmt.Printf("ℹ️   Daemon already running for %q (pid %d).\n", cfg.Session, pid)
+		return nil
+	}
+	if !tmux.HasSession(cfg.Session) {
+		return fmt.Errorf("session %q is not running", cfg.Session)
+	}
+	if _, err := limitParser(cfg); err != nil {
+		return err
+	}
+	unlock, ok, err := state.TryLock(state.DaemonLock(cfg.Session))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Printf("ℹ️   Daemon already starting for %q.\n", cfg.Session)
+		return nil
+	}
+	unlock()
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	logFile, err := os.OpenFile(logPath(cfg.Session), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening daemon log: %w", err)
+	}
+	defer logFile.Close()
+
+	proc := exec.Command(exe, "daemon", "run", "-s", cfg.Session)
+	proc.Stdout = logFile
+	proc.Stderr = logFile
+	proc.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := proc.Start(); err != nil {
+		return fmt.Errorf("starting daemon: %w", err)
+	}
+	_ = proc.Process.Release()
+
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+		if pid, ok := state.DaemonPid(cfg.Session); ok {
+			fmt.Printf("🟢  Daemon started for %q (pid %d, log: %s)\n", cfg.Session, pid, logPath(cfg.Session))
+			return nil
+		}
+	}
+	return fmt.Errorf("daemon did not start — see %s", logPath(cfg.Session))
+}
+
+// stopDaemon sends SIGTERM to the session's daemon and waits for it to exit.
+func stopDaemon(cfg *config.Config) error {
+	pid, ok := state.DaemonPid(cfg.Session)
+	if !ok {
+		fmt.Printf("ℹ️   No daemon running for %q.\n", cfg.Session)
+		return nil
+	}
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+		return fmt.Errorf("stopping daemon (pid %d): %w", pid, err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+		if _, ok := state.DaemonPid(cfg.Session); !ok {
+			fmt.Printf("🔴  Daemon stopped (pid %d).\n", pid)
+			return nil
+		}
+	}
+	return fmt.Errorf("daemon (pid %d) did not exit after SIGTERM", pid)
+}
+
+// runDaemon supervises the session's monitors until the session ends or the
+// process receives SIGTERM/SIGINT.
+func runDaemon(cfg *config.Config) error {
+	limits, err := limitParser(cfg)
+	if err != nil {
+		return err
+	}
+	// The pidfile is only written and removed under the daemon lock, so a
+	// second daemon cannot replace or delete the running one's.
+	unlockDaemon, ok, err := state.TryLock(state.DaemonLock(cfg.Session))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if pid, running := state.DaemonPid(cfg.Session); running {
+			return fmt.Errorf("daemon already running for %q (pid %d)", cfg.Session, pid)
+		}
+		return fmt.Errorf("daemon already running for %q", cfg.Session)
+	}
+	defer unlockDaemon()
+	if err := state.WritePid(cfg.Session); err != nil {
+		return err
+	}
+	defer state.RemovePid(cfg.Session)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	// An attached client may be monitoring in-process; take over when it stops.
+	for waited := false; ; waited = true {
+		unlock, ok, err := state.TryLock(state.MonitorLock(cfg.Session))
+		if err != nil {
+			return err
+		}
+		if ok {
+			defer unlock()
+			break
+		}
+		if !waited {
+			fmt.Printf("%s [daemon] Waiting for the monitors of another claude-swarm process to stop.\n", time.Now().UTC().Format("2006-01-02T15:04:05Z"))
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Second):
+		}
+	}
+
+	fmt.Printf("%s [daemon] Monitoring session %q (pid %d).\n", time.Now().UTC().Format("2006-01-02T15:04:05Z"), cfg.Session, os.Getpid())
+	monitor.Supervise(ctx, cfg, limits, cfg.Session, monitorTargets(cfg.Session), os.Stdout)
+	fmt.Printf("%s [daemon] Exiting.\n", time.Now().UTC().Format("2006-01-02T15:04:05Z"))
+	return nil
+}
+
+// monitorTargets returns a target source backed by the session manifest, so
+// monitors follow workers added or replaced after startup.
+func monitorTargets(session string) func() ([]monitor.Target, error) {
+	return func() ([]monitor.Target, error) {
+		m, err := state.Load(session)
+		if err != nil {
+			return nil, err
+		}
+		targets := make([]monitor.Target, 0, len(m.Workers))
+		for _, wk := range m.Workers {
+			if wk.PaneID == "" {
+				continue
+			}
+			targets = append(targets, monitor.Target{
+				PaneID:    wk.PaneID,
+				Worker:    wk.Index,
+				CLI:       wk.CLI,
+				ResumeCmd: resumeCmdFor(wk),
+				Title:     paneTitle(wk),
+			})
+		}
+		return targets, nil
+	}
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/cpoulin/claude-swarm/internal/config"
+	"github.com/cpoulin/claude-swarm/internal/state"
+	"github.com/cpoulin/claude-swarm/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+// Worktree policies applied by down.
+const (
+	policyKeep    = "keep"
+	policyRemove  = "remove"
+	policyArchive = "archive"
+)
+
+var downCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Stop a swarm: kill the session, stop monitors and apply a worktree policy",
+	Long: `Tears a swarm down without needing the attached client:
+  --keep     leave every worktree and branch in place
+  --remove   remove clean workers; unmerged, unpushed or dirty ones need
+             confirmation (or --yes) and are kept when there is no TTY
+  --archive  archive every worker under refs/swarm-archive/, then remove all
+Without a policy flag the down_policy setting is used (default: remove).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		policy := cfg.DownPolicy
+		for _, p := range []string{policyKeep, policyRemove, policyArchive} {
+			if on, _ := cmd.Flags().GetBool(p); on {
+				policy = p
+			}
+		}
+		if yes, _ := cmd.Flags().GetBool("yes"); yes {
+			cfg.Force = true
+		}
+		return runDown(cfg, policy)
+	},
+}
+
+func init() {
+	f := downCmd.Flags()
+	f.Bool(policyKeep, false, "Keep all worktrees and branches")
+	f.Bool(policyRemove, false, "Remove worktrees; ask before removing risky ones")
+	f.Bool(policyArchive, false, "Archive every worker, then remove all worktrees")
+	f.BoolP("yes", "y", false, "Do not ask; also remove unmerged, unpushed or dirty worktrees")
+	downCmd.MarkFlagsMutuallyExclusive(policyKeep, policyRemove, policyArchive)
+	rootCmd.AddCommand(downCmd)
+}
+
+// runDown kills the session, stops its monitor daemon and applies policy to
+// the recorded workers. It never reads stdin unless stdin is a terminal.
+func runDown(cfg *config.Config, policy string) error {
+	switch policy {
+	case policyKeep, policyRemove, policyArchive:
+	default:
+		return fmt.Errorf("unknown down policy %q — use keep, remove, or archive", policy)
+	}
+
+	if tmux.HasSession(cfg.Session) {
+		if err := tmux.KillSession(cfg.Session); err != nil {
+			return err
+		}
+		fmt.Printf("🔴  Session %q stopped.\n", cfg.Session)
+	}
+	if _, ok := state.DaemonPid(cfg.Session); ok {
+		_ = stopDaemon(cfg)
+	}
+
+	unlock, err := lockSession(cfg.Session)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	m, err := state.Load(cfg.Session)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Printf("ℹ️   No workers recorded for %q.\n", cfg.Session)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for i := range m.Workers {
+		m.Workers[i].PaneID = ""
+	}
+
+	if policy == policyKeep {
+		for _, wk := range m.Workers {
+			fmt.Printf("ℹ️   Kept worker-%d: %s\n", wk.Index, wk.Worktree)
+		}
+		return state.Save(m)
+	}
+	if policy == policyArchive {
+		cfg.Archive = true
+		cfg.Force = true
+	}
+
+	checks := make([]workerCheck, 0, len(m.Workers))
+	for _, wk := range m.Workers {
+		checks = append(checks, checkWorker(wk))
+	}
+	printCheckTable(checks)
+	fmt.Println()
+
+	for _, idx := range cleanupWorkers(cfg, cfg.Session, checks, bufio.NewReader(os.Stdin)) {
+		m.RemoveWorker(idx)
+	}
+	if len(m.Workers) == 0 {
+		fmt.Println("✅  Cleaned up.")
+		return state.Remove(cfg.Session)
+	}
+	fmt.Printf("✅  Cleaned up; %d worker(s) kept.\n", len(m.Workers))
+	return state.Save(m)
+}
+
+// isTerminal reports whether stdin is an interactive terminal: a character
+// device other than /dev/null.
+func isTerminal() bool {
+	fi, err := os.Stdin.Stat()
+	if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	null, err := os.Stat(os.DevNull)
+	return err != nil || !os.SameFile(fi, null)
+}
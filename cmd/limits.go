@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/cpoulin/claude-swarm/internal/config"
+	"github.com/cpoulin/claude-swarm/internal/usagelimit"
+	"github.com/spf13/cobra"
+)
+
+// anyProvider is the limit_rules key for rules that apply to every CLI.
+const anyProvider = "any"
+
+var limitsCmd = &cobra.Command{
+	Use:   "limits",
+	Short: "Check how usage-limit messages are recognised",
+	Long: `Usage-limit messages are recognised by built-in rules plus the limit_rules
+of the config file, so new CLI wording can be handled without a new release.
+Only the bottom limit_status_lines of a screen are searched, skipping lines the
+CLI renders as quoted content; save a screen with its styling for the same
+result the monitor gets:
+
+  tmux capture-pane -e -p -t <pane> > screen.txt`,
+}
+
+func init() {
+	testCmd := &cobra.Command{
+		Use:   "test <file | ->",
+		Short: "Show which rule matches a saved screen and when the worker would resume",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runLimitsTest,
+	}
+	testCmd.Flags().String("cli", "", "Only use the rules for this CLI (claude|gemini|codex)")
+	limitsCmd.AddCommand(testCmd)
+	rootCmd.AddCommand(limitsCmd)
+}
+
+// limitRules converts the limit_rules of the config, sorted by provider.
+func limitRules(cfg *config.Config) []usagelimit.Rule {
+	providers := make([]string, 0, len(cfg.LimitRules))
+	for p := range cfg.LimitRules {
+		providers = append(providers, p)
+	}
+	slices.Sort(providers)
+	var rules []usagelimit.Rule
+	for _, p := range providers {
+		for i, r := range cfg.LimitRules[p] {
+			rule := usagelimit.Rule{
+				Name:        r.Name,
+				Provider:    p,
+				Kind:        usagelimit.Kind(r.Kind),
+				Match:       r.Match,
+				Reset:       r.Reset,
+				DefaultWait: r.DefaultWait,
+			}
+			if p == anyProvider {
+				rule.Provider = ""
+			}
+			if rule.Name == "" {
+				rule.Name = fmt.Sprintf("%s-%d", p, i+1)
+			}
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// limitParser returns a parser for the built-in rules and the config's.
+func limitParser(cfg *config.Config) (*usagelimit.Parser, error) {
+	for p := range cfg.LimitRules {
+		if p != anyProvider && !isSupportedCLIType(p) {
+			return nil, fmt.Errorf("limit_rules: unknown provider %q — use claude, gemini, codex, or any", p)
+		}
+	}
+	parser, err := usagelimit.NewParser(limitRules(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("limit_rules: %w", err)
+	}
+	return parser.WithStatusLines(cfg.LimitLines), nil
+}
+
+// monitorLimits is limitParser for in-process monitors, which fall back to
+// the built-in rules rather than stop the command over a bad rule.
+func monitorLimits(cfg *config.Config) *usagelimit.Parser {
+	parser, err := limitParser(cfg)
+	if err != nil {
+		fmt.Printf("⚠️   %v — using the built-in usage-limit rules only.\n", err)
+		return usagelimit.Default().WithStatusLines(cfg.LimitLines)
+	}
+	return parser
+}
+
+func runLimitsTest(cmd *cobra.Command, args []string) error {
+	cli, _ := cmd.Flags().GetString("cli")
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if cli != "" && !isSupportedCLIType(cli) {
+		return fmt.Errorf("unsupported CLI %q — use claude, gemini, or codex", cli)
+	}
+	var data []byte
+	name := args[0]
+	if name == "-" {
+		name = "stdin"
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return err
+	}
+	parser, err := limitParser(cfg)
+	if err != nil {
+		return err
+	}
+
+	custom := limitRules(cfg)
+	fmt.Printf("📋  %d rule(s) from limit_rules, %d built-in\n", len(custom), len(usagelimit.Builtin))
+	now := time.Now()
+	r := parser.Parse(cli, parser.Screen(cli, string(data)), now)
+	if !r.Found {
+		if all := parser.Parse(cli, usagelimit.Strip(string(data)), now); all.Found {
+			return fmt.Errorf("no rule matches the status lines of %s; rule %q matches %q, but above them or in quoted content",
+				name, all.Rule, all.Matched)
+		}
+		return fmt.Errorf("no rule matches %s", name)
+	}
+	source := "built-in"
+	if slices.ContainsFunc(custom, func(c usagelimit.Rule) bool { return c.Name == r.Rule }) {
+		source = "limit_rules"
+	}
+	resume := r.ResetAt.Add(time.Duration(cfg.ResumeBufferSec) * time.Second)
+	if r.Wait == 0 {
+		resume = now.Add(time.Duration(cfg.ResumeBufferSec) * time.Second)
+	}
+	provider := r.Provider
+	if provider == "" {
+		provider = "unknown"
+	}
+	const layout = "Mon 2 Jan 15:04 MST"
+	fmt.Printf("✅  Matched rule %q (%s)\n", r.Rule, source)
+	fmt.Printf("    Provider:   %s\n", provider)
+	fmt.Printf("    Kind:       %s\n", r.Kind)
+	fmt.Printf("    Message:    %s\n", r.Matched)
+	fmt.Printf("    Resets:     %s (%s)\n", r.ResetAt.Format(layout), r.Confidence)
+	fmt.Printf("    Resume at:  %s, in %s (resume_buffer_secs %d)\n",
+		resume.Local().Format(layout), resume.Sub(now).Round(time.Second), cfg.ResumeBufferSec)
+	return nil
+}
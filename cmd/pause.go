@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cpoulin/claude-swarm/internal/monitor"
+	"github.com/cpoulin/claude-swarm/internal/proc"
+	"github.com/cpoulin/claude-swarm/internal/state"
+	"github.com/cpoulin/claude-swarm/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause [-w N | --all]",
+	Short: "Freeze workers without losing their context",
+	Long: `Stops the CLI of each selected worker, and every process it started, with
+SIGSTOP. The CLI keeps its conversation and picks up exactly where it was on
+resume. Paused panes are titled [paused]; monitors and the task queue leave
+them alone and send skips them.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPause(cmd, true)
+	},
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume [-w N | --all]",
+	Short: "Continue paused workers",
+	Long: `Sends SIGCONT to the processes of each selected worker paused with pause
+and brings the CLI back to the foreground of its pane.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPause(cmd, false)
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{pauseCmd, resumeCmd} {
+		c.Flags().IntSliceP("worker", "w", nil, "Worker number(s), e.g. -w 2 or -w 2,3")
+		c.Flags().Bool("all", false, "Every worker of the session")
+		rootCmd.AddCommand(c)
+	}
+}
+
+// runPause pauses (or resumes) the workers picked with -w or --all.
+func runPause(cmd *cobra.Command, pause bool) error {
+	indices, _ := cmd.Flags().GetIntSlice("worker")
+	all, _ := cmd.Flags().GetBool("all")
+	if all == (len(indices) > 0) {
+		return fmt.Errorf("pick the worker(s) with -w, or use --all")
+	}
+	if all {
+		indices = nil
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if !tmux.HasSession(cfg.Session) {
+		return fmt.Errorf("session %q is not running", cfg.Session)
+	}
+	m, err := state.Load(cfg.Session)
+	if err != nil {
+		return err
+	}
+	targets, err := selectWorkers(m, indices, nil)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, wk := range targets {
+		if err := setPaused(wk, pause); err != nil {
+			fmt.Printf("⚠️   worker-%d: %v\n", wk.Index, err)
+			failed++
+		}
+	}
+	if failed == len(targets) {
+		return fmt.Errorf("no worker changed")
+	}
+	return nil
+}
+
+// setPaused stops or continues the processes in a worker's pane and marks
+// the pane accordingly. The mark is set before stopping, so a monitor never
+// reads the frozen screen as the worker's state.
+func setPaused(wk state.Worker, pause bool) error {
+	if paused := monitor.IsPaused(wk.PaneID); paused == pause {
+		if paused {
+			fmt.Printf("ℹ️   worker-%d is already paused\n", wk.Index)
+		} else {
+			fmt.Printf("ℹ️   worker-%d is not paused\n", wk.Index)
+		}
+		return nil
+	}
+	out, err := tmux.DisplayFormat(wk.PaneID, "#{pane_pid}")
+	if err != nil {
+		return err
+	}
+	pid, err := strconv.Atoi(out)
+	if err != nil {
+		return fmt.Errorf("reading pane pid: %w", err)
+	}
+
+	if !pause {
+		if _, err := proc.Cont(pid); err != nil {
+			return err
+		}
+		// The pane's shell saw its job stop and took the terminal back; fg
+		// hands it to the CLI again.
+		if command, err := tmux.DisplayFormat(wk.PaneID, "#{pane_current_command}"); err == nil && monitor.IsShell(command) {
+			_ = tmux.SendKeys(wk.PaneID, "fg")
+		}
+		if err := monitor.MarkPaused(wk.PaneID, false); err != nil {
+			return err
+		}
+		// The monitor adds the worker's state back at its next poll.
+		_ = tmux.SetPaneTitle(wk.PaneID, paneTitle(wk))
+		fmt.Printf("▶️   Resumed worker-%d\n", wk.Index)
+		return nil
+	}
+
+	if err := monitor.MarkPaused(wk.PaneID, true); err != nil {
+		return err
+	}
+	n, err := proc.Stop(pid)
+	if err != nil {
+		_, _ = proc.Cont(pid)
+		_ = monitor.MarkPaused(wk.PaneID, false)
+		return err
+	}
+	_ = tmux.SetPaneTitle(wk.PaneID, fmt.Sprintf("%s [%s]", paneTitle(wk), monitor.Paused))
+	fmt.Printf("⏸   Paused worker-%d (%d process(es))\n", wk.Index, n)
+	return nil
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/cpoulin/claude-swarm/internal/config"
+	"github.com/cpoulin/claude-swarm/internal/state"
+	"github.com/cpoulin/claude-swarm/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var rmCmd = &cobra.Command{
+	Use:   "rm -w N",
+	Short: "Retire one worker: close its pane and clean up its worktree",
+	Long: `Kills the worker's pane (its monitor stops with it), reflows the remaining
+panes and cleans up the worktree and branch like down --remove: a clean
+worker is removed, an unmerged, unpushed or dirty one only after confirmation
+(or --yes), and it is archived first when archive_on_cleanup is set.`,
+	RunE: runRm,
+}
+
+func init() {
+	f := rmCmd.Flags()
+	f.IntP("worker", "w", 0, "Worker number to remove")
+	f.Bool(policyKeep, false, "Keep the worktree and branch")
+	f.BoolP("yes", "y", false, "Do not ask; also remove an unmerged, unpushed or dirty worktree")
+	_ = rmCmd.MarkFlagRequired("worker")
+	rootCmd.AddCommand(rmCmd)
+}
+
+func runRm(cmd *cobra.Command, args []string) error {
+	idx, _ := cmd.Flags().GetInt("worker")
+	keep, _ := cmd.Flags().GetBool(policyKeep)
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		cfg.Force = true
+	}
+
+	unlock, err := lockSession(cfg.Session)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	m, err := state.Load(cfg.Session)
+	if err != nil {
+		return err
+	}
+	wk := m.Worker(idx)
+	if wk == nil {
+		return fmt.Errorf("no worker-%d in session %q", idx, cfg.Session)
+	}
+	if wk.PaneID != "" && tmux.HasSession(cfg.Session) {
+		live := 0
+		for _, other := range m.Workers {
+			if other.PaneID != "" {
+				live++
+			}
+		}
+		if live == 1 {
+			return fmt.Errorf("worker-%d is the last running worker — use claude-swarm down instead", idx)
+		}
+	}
+	return retireWorker(cfg, m, idx, keep, bufio.NewReader(os.Stdin))
+}
+
+// retireWorker closes a worker's pane, reflows its window and, unless keep is
+// set, cleans up its worktree and branch under the safe cleanup policy. A
+// worker whose worktree is kept stays in the manifest without a pane.
+func retireWorker(cfg *config.Config, m *state.Manifest, idx int, keep bool, stdin *bufio.Reader) error {
+	wk := m.Worker(idx)
+	if wk.PaneID != "" && tmux.HasSession(cfg.Session) {
+		window, _ := tmux.DisplayFormat(wk.PaneID, "#{window_id}")
+		if err := tmux.KillPane(wk.PaneID); err != nil {
+			return err
+		}
+		if window != "" {
+			_ = tmux.SelectLayout(window, cfg.Layout)
+		}
+		fmt.Printf("🔴  Closed worker-%d's pane.\n", idx)
+	}
+	wk.PaneID = ""
+	// Saving now stops the worker's monitor at the next resync, even if the
+	// cleanup below is declined.
+	if err := state.Save(m); err != nil {
+		return err
+	}
+
+	if keep {
+		fmt.Printf("ℹ️   Kept worker-%d: %s\n", idx, wk.Worktree)
+		return nil
+	}
+	c := checkWorker(*wk)
+	printCheckTable([]workerCheck{c})
+	fmt.Println()
+	if removed := cleanupWorkers(cfg, cfg.Session, []workerCheck{c}, stdin); len(removed) > 0 {
+		m.RemoveWorker(idx)
+		fmt.Printf("✅  Removed worker-%d.\n", idx)
+	}
+	return state.Save(m)
+}
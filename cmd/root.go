@@ -1,20 +1,26 @@
 package cmd
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cpoulin/claude-swarm/internal/config"
 	"github.com/cpoulin/claude-swarm/internal/git"
+	"github.com/cpoulin/claude-swarm/internal/layout"
 	"github.com/cpoulin/claude-swarm/internal/monitor"
+	"github.com/cpoulin/claude-swarm/internal/spec"
+	"github.com/cpoulin/claude-swarm/internal/state"
 	"github.com/cpoulin/claude-swarm/internal/tmux"
+	"github.com/cpoulin/claude-swarm/internal/usagelimit"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -23,12 +29,15 @@ var rootCmd = &cobra.Command{
 	Use:   "claude-swarm",
 	Short: "Spawn N AI CLI instances in git worktrees inside tmux",
 	Long: `claude-swarm creates a tmux session with:
-  - Window 1 "swarm": all N agents visible as stacked panes
+  - Window 1 "swarm": one pane per agent (overflow goes to "swarm-2", "swarm-3", …)
   - Window 2 "hub":   nvim (left) + lazygit (right)`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.Load()
+		cfg, err := loadConfig()
 		if err != nil {
-			return fmt.Errorf("loading config: %w", err)
+			return err
+		}
+		if err := resolveSpec(cmd, cfg); err != nil {
+			return err
 		}
 		return orchestrate(cfg)
 	},
@@ -43,20 +52,34 @@ func Execute() {
 func init() {
 	cobra.OnInitialize(initConfig)
 
+	pf := rootCmd.PersistentFlags()
+	pf.StringP("session", "s", "", "tmux session name (default: swarm-<repo>, or the swarm owning the current worktree)")
+	pf.Bool("force", false, "Remove unmerged, unpushed or dirty worktrees during cleanup without asking")
+	_ = viper.BindPFlag("session", pf.Lookup("session"))
+	_ = viper.BindPFlag("force", pf.Lookup("force"))
+
 	f := rootCmd.Flags()
 	f.IntP("num", "n", 0, "Number of AI instances (default: 4)")
-	f.StringP("session", "s", "", "tmux session name (default: claude-swarm)")
 	f.StringP("base-branch", "b", "", "Base branch for worktrees (default: current branch)")
 	f.StringP("type", "t", "", "AI CLI(s) to use: claude|gemini|codex (or comma list, e.g. claude,gemini,codex)")
 	f.String("cli-flags", "", "Extra flags passed to each AI CLI command")
 	f.BoolP("add", "a", false, "Add workers to an existing session instead of restarting")
+	f.String("spec", "", "Swarm spec file listing each worker (default: swarm.yaml in the repo, unless -n/-t is given)")
+	f.String("tasks", "", "YAML file assigning an initial prompt to each worker")
+	f.String("layout", "", "Pane layout: tiled|main-vertical|even-horizontal (default: tiled)")
+	f.Int("max-panes", 0, "Max worker panes per window before spilling into swarm-2, swarm-3… (default: 6)")
+	f.String("worktree-root", "", "Directory for worktrees, e.g. ~/.cache/claude-swarm/{repo}/{session} (default: inside the repo)")
 
 	_ = viper.BindPFlag("num", f.Lookup("num"))
-	_ = viper.BindPFlag("session", f.Lookup("session"))
 	_ = viper.BindPFlag("base_branch", f.Lookup("base-branch"))
 	_ = viper.BindPFlag("cli_type", f.Lookup("type"))
 	_ = viper.BindPFlag("cli_flags", f.Lookup("cli-flags"))
 	_ = viper.BindPFlag("add_mode", f.Lookup("add"))
+	_ = viper.BindPFlag("spec", f.Lookup("spec"))
+	_ = viper.BindPFlag("tasks", f.Lookup("tasks"))
+	_ = viper.BindPFlag("layout", f.Lookup("layout"))
+	_ = viper.BindPFlag("max_panes_per_window", f.Lookup("max-panes"))
+	_ = viper.BindPFlag("worktree_root", f.Lookup("worktree-root"))
 }
 
 func initConfig() {
@@ -69,45 +92,189 @@ func initConfig() {
 	_ = viper.ReadInConfig()
 }
 
+// loadConfig loads the merged configuration and resolves the session name:
+// an explicit -s wins, then the swarm owning the current worktree, then the
+// session the swarm spec names, then a name derived from the repository so
+// swarms in different repos don't collide.
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	if cfg.Session == "" {
+		cfg.Session = defaultSession(specSession(cfg.Spec))
+	}
+	return cfg, nil
+}
+
+// defaultSession is the session of a command run without -s: the swarm
+// owning the current worktree, else fromSpec, else one named after the repo.
+func defaultSession(fromSpec string) string {
+	if cwd, err := os.Getwd(); err == nil {
+		if m, _, err := state.FindWorktree(cwd); err == nil {
+			return m.Session
+		}
+	}
+	if fromSpec != "" {
+		return fromSpec
+	}
+	root, err := git.MainRoot()
+	if err != nil {
+		return "claude-swarm"
+	}
+	return "swarm-" + invalidSessionChars.ReplaceAllString(filepath.Base(root), "-")
+}
+
+// invalidSessionChars matches characters that tmux session names, worktree
+// directories or branch names cannot safely contain.
+var invalidSessionChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
 // ── Naming helpers ─────────────────────────────────────────────────────────────
 
-func wtDir(repoRoot, prefix string, i int) string {
-	return filepath.Join(repoRoot, fmt.Sprintf("%s-%d", prefix, i))
+// wtDir and wtBranch are namespaced by session so concurrent swarms in the
+// same repository never touch each other's worktrees or branches.
+func wtDir(cfg *config.Config, repoRoot string, i int) string {
+	if root := wtRoot(cfg, repoRoot); root != "" {
+		return filepath.Join(root, fmt.Sprintf("worker-%d", i))
+	}
+	return filepath.Join(repoRoot, fmt.Sprintf("%s-%s-%d", cfg.WorktreePrefix, cfg.Session, i))
 }
 
-func wtBranch(baseBranch string, i int) string {
-	return fmt.Sprintf("swarm/%s/worker-%d", baseBranch, i)
+// wtRoot expands the worktree_root setting ("~", "{repo}", "{session}") into
+// the directory holding this session's worktrees, or "" for in-repo worktrees.
+// A root without "{session}" gets the session appended so swarms stay apart,
+// and a relative root is taken from the repository root.
+func wtRoot(cfg *config.Config, repoRoot string) string {
+	root := cfg.WorktreeRoot
+	if root == "" {
+		return ""
+	}
+	if !strings.Contains(root, "{session}") {
+		root = filepath.Join(root, "{session}")
+	}
+	if rest, ok := strings.CutPrefix(root, "~"); ok {
+		home, _ := os.UserHomeDir()
+		root = home + rest
+	}
+	root = strings.NewReplacer("{repo}", filepath.Base(repoRoot), "{session}", cfg.Session).Replace(root)
+	if !filepath.IsAbs(root) {
+		root = filepath.Join(repoRoot, root)
+	}
+	return filepath.Clean(root)
 }
 
-func paneTitle(i int, cliType string) string {
-	return fmt.Sprintf("worker-%d (%s)", i, cliType)
+// wtExclude returns the .git/info/exclude pattern for a worktree dir inside
+// the repository: the prefixed worktree dirs by default, or the session's
+// directory under worktree_root. ok is false for a worktree outside the repo.
+func wtExclude(cfg *config.Config, repoRoot, dir string) (pattern string, ok bool) {
+	parent := filepath.Dir(dir)
+	rel, err := filepath.Rel(repoRoot, parent)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	if rel == "." {
+		return "/" + cfg.WorktreePrefix + "-*/", true
+	}
+	return "/" + filepath.ToSlash(rel) + "/", true
+}
+
+func wtBranch(session, baseBranch string, i int) string {
+	return fmt.Sprintf("swarm/%s/%s/worker-%d", session, baseBranch, i)
+}
+
+func paneTitle(wk state.Worker) string {
+	title := fmt.Sprintf("worker-%d (%s)", wk.Index, wk.Spec())
+	if wk.Label != "" {
+		title += " " + wk.Label
+	}
+	return title
 }
 
 // ── Validation ────────────────────────────────────────────────────────────────
 
-func validate(cfg *config.Config) error {
+func validate(cfg *config.Config, workers []spec.Worker) error {
 	if _, err := exec.LookPath("tmux"); err != nil {
 		return fmt.Errorf("tmux not found — install it first")
 	}
 	if _, err := git.RepoRoot(); err != nil {
 		return fmt.Errorf("not inside a git repository")
 	}
-	cliTypes := parseCLITypes(cfg.CLIType)
-	if len(cliTypes) == 0 {
+	if invalidSessionChars.MatchString(cfg.Session) {
+		return fmt.Errorf("invalid session name %q — use letters, digits, '-' and '_'", cfg.Session)
+	}
+	if len(workers) == 0 {
 		return fmt.Errorf("no valid CLI types provided")
 	}
-	for _, cliType := range cliTypes {
-		if !isSupportedCLIType(cliType) {
-			cliName, _ := parseWorker(cliType)
-			return fmt.Errorf("unknown CLI type %q — use claude, gemini, or codex", cliName)
+	for i, wk := range workers {
+		if !isSupportedCLIType(wk.CLI) {
+			return fmt.Errorf("unknown CLI type %q — use claude, gemini, or codex", wk.CLI)
 		}
-		cliName, _ := parseWorker(cliType)
-		if _, err := exec.LookPath(cliName); err != nil {
-			return fmt.Errorf("%s not found — install it first", cliName)
+		if _, err := exec.LookPath(wk.CLI); err != nil {
+			return fmt.Errorf("%s not found — install it first", wk.CLI)
 		}
+		if wk.Dir != "" && !filepath.IsLocal(wk.Dir) {
+			return fmt.Errorf("worker %d: dir %q must be a relative path inside the worktree", i+1, wk.Dir)
+		}
+	}
+	if !layout.Valid(cfg.Layout) {
+		return fmt.Errorf("unknown layout %q — use tiled, main-vertical, or even-horizontal", cfg.Layout)
 	}
-	if cfg.Num < 1 {
-		return fmt.Errorf("-n must be a positive integer")
+	if cfg.PromptDelivery != promptArg && cfg.PromptDelivery != promptKeys {
+		return fmt.Errorf("unknown prompt_delivery %q — use arg or keys", cfg.PromptDelivery)
+	}
+	switch cfg.StallAction {
+	case monitor.StallNone, monitor.StallNudge, monitor.StallInterrupt:
+	default:
+		return fmt.Errorf("unknown stall_action %q — use none, nudge, or interrupt", cfg.StallAction)
+	}
+	return nil
+}
+
+// specSession returns the session named by the spec at path, or by the
+// repository's swarm.yaml if path is empty, so every command finds a swarm
+// started from a spec; "" if there is none.
+func specSession(path string) string {
+	if path == "" {
+		root, err := git.MainRoot()
+		if err != nil {
+			return ""
+		}
+		if path, _ = spec.Find(root); path == "" {
+			return ""
+		}
+	}
+	sp, err := spec.Load(path)
+	if err != nil {
+		return ""
+	}
+	return sp.Session
+}
+
+// resolveSpec picks the spec file to use — --spec, or a swarm.yaml in the
+// repository when neither -n nor -t was given — and lets it supply the
+// session and base branch unless those were set explicitly.
+func resolveSpec(cmd *cobra.Command, cfg *config.Config) error {
+	if cfg.Spec == "" && !cmd.Flags().Changed("num") && !cmd.Flags().Changed("type") {
+		if root, err := git.MainRoot(); err == nil {
+			cfg.Spec, _ = spec.Find(root)
+		}
+	}
+	if cfg.Spec == "" {
+		// A swarm started from -n/-t is not the one the repo's swarm.yaml names.
+		if cmd.Flags().Lookup("num") != nil && viper.GetString("session") == "" {
+			cfg.Session = defaultSession("")
+		}
+		return nil
+	}
+	sp, err := spec.Load(cfg.Spec)
+	if err != nil {
+		return err
+	}
+	if sp.Session != "" && !cmd.Flags().Changed("session") {
+		cfg.Session = sp.Session
+	}
+	if sp.BaseBranch != "" && cfg.BaseBranch == "" {
+		cfg.BaseBranch = sp.BaseBranch
 	}
 	return nil
 }
@@ -115,106 +282,211 @@ func validate(cfg *config.Config) error {
 // ── Orchestrate ───────────────────────────────────────────────────────────────
 
 func orchestrate(cfg *config.Config) error {
-	if err := validate(cfg); err != nil {
+	workers, repoRoot, err := planSwarm(cfg)
+	if err != nil {
 		return err
 	}
-	workers := buildWorkers(cfg)
+
+	w, closeLog := openLog(cfg.Session)
+	defer closeLog()
+
+	fmt.Printf("🌳  Repo    : %s\n", repoRoot)
+	fmt.Printf("🌿  Branch  : %s\n", cfg.BaseBranch)
+	fmt.Printf("🤖  Instances: %d  (CLI mix: %s)\n", len(workers), strings.Join(uniqueWorkerTypes(workers), ","))
+	if cfg.Spec != "" {
+		fmt.Printf("📄  Spec    : %s\n", cfg.Spec)
+	}
+	fmt.Printf("📺  Session : %s\n", cfg.Session)
+	fmt.Printf("📋  Log     : %s\n\n", logPath(cfg.Session))
+
+	if cfg.AddMode {
+		unlock, err := lockSession(cfg.Session)
+		if err != nil {
+			return err
+		}
+		added, err := addWorkers(cfg, repoRoot, workers)
+		unlock()
+		if err != nil {
+			return err
+		}
+		deliverPrompts(cfg, added, os.Stdout)
+		return nil
+	}
+	return startSwarm(cfg, repoRoot, workers, w)
+}
+
+// planSwarm builds and validates the worker list and resolves the base branch.
+func planSwarm(cfg *config.Config) ([]spec.Worker, string, error) {
+	workers, err := buildWorkers(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := validate(cfg, workers); err != nil {
+		return nil, "", err
+	}
 	workers = normalizeWorkers(workers)
 
-	repoRoot, err := git.RepoRoot()
+	repoRoot, err := git.MainRoot()
 	if err != nil {
-		return err
+		return nil, "", err
 	}
 
+	if cfg.BaseBranch == "" && cfg.AddMode {
+		if m, err := state.Load(cfg.Session); err == nil && len(m.Workers) > 0 {
+			cfg.BaseBranch = m.Workers[0].BaseBranch
+		}
+	}
 	if cfg.BaseBranch == "" {
 		cfg.BaseBranch, err = git.CurrentBranch()
 		if err != nil {
-			return err
+			return nil, "", err
 		}
 	}
+	return workers, repoRoot, nil
+}
+
+// logPath returns the monitor log file of a session.
+func logPath(session string) string {
+	return fmt.Sprintf("/tmp/claude-swarm-%s.log", session)
+}
 
-	logPath := fmt.Sprintf("/tmp/claude-swarm-%s.log", cfg.Session)
-	logFile, _ := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
-	if logFile != nil {
-		defer logFile.Close()
+// openLog returns a writer that tees to stdout and the session log file.
+func openLog(session string) (io.Writer, func()) {
+	logFile, _ := os.OpenFile(logPath(session), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if logFile == nil {
+		return os.Stdout, func() {}
 	}
+	return io.MultiWriter(os.Stdout, logFile), func() { logFile.Close() }
+}
 
-	fmt.Printf("🌳  Repo    : %s\n", repoRoot)
-	fmt.Printf("🌿  Branch  : %s\n", cfg.BaseBranch)
-	fmt.Printf("🤖  Instances: %d  (CLI mix: %s)\n", len(workers), strings.Join(uniqueWorkerTypes(workers), ","))
-	fmt.Printf("📺  Session : %s\n", cfg.Session)
-	fmt.Printf("📋  Log     : %s\n\n", logPath)
+// ── Start swarm ───────────────────────────────────────────────────────────────
 
-	var w io.Writer = os.Stdout
-	if logFile != nil {
-		w = io.MultiWriter(os.Stdout, logFile)
+// startSwarm launches the swarm and attaches to it while the initial prompts
+// are delivered, then waits for the delivery to finish.
+func startSwarm(cfg *config.Config, repoRoot string, workers []spec.Worker, w io.Writer) error {
+	started, err := launchSwarm(cfg, repoRoot, workers)
+	if err != nil {
+		return err
 	}
-
-	if cfg.AddMode {
-		return addWorkers(cfg, repoRoot, workers)
+	delivered := make(chan struct{})
+	go func() {
+		defer close(delivered)
+		deliverPrompts(cfg, started, w)
+	}()
+	err = runAndMonitor(cfg, w)
+	select {
+	case <-delivered:
+	default:
+		fmt.Println("⏳  Waiting for the initial prompts to be delivered…")
+		<-delivered
 	}
-	return startSwarm(cfg, repoRoot, workers, w)
+	return err
 }
 
-// ── Start swarm ───────────────────────────────────────────────────────────────
+// launchSwarm creates the worktrees and the tmux session with every worker
+// running, without attaching to it or delivering prompts. It returns the
+// started workers. It holds the session lock throughout, so scale, rm or
+// attach run meanwhile wait for the new manifest instead of overwriting it.
+func launchSwarm(cfg *config.Config, repoRoot string, workers []spec.Worker) ([]state.Worker, error) {
+	unlock, err := lockSession(cfg.Session)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-func startSwarm(cfg *config.Config, repoRoot string, workers []string, w io.Writer) error {
 	if tmux.HasSession(cfg.Session) {
-		fmt.Printf("⚠️   Session %q already exists — killing it.\n", cfg.Session)
+		fmt.Printf("⚠️   Session %q already exists — killing it (use claude-swarm attach to reconnect instead).\n", cfg.Session)
 		_ = tmux.KillSession(cfg.Session)
 	}
 
-	worktreeDirs, err := createWorktrees(cfg, repoRoot, workers)
+	baseCommit, err := git.RevParse(cfg.BaseBranch)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	m := &state.Manifest{
+		Session:   cfg.Session,
+		RepoRoot:  repoRoot,
+		CreatedAt: time.Now().UTC(),
+	}
+	for i, sw := range workers {
+		wk, err := createWorktree(cfg, repoRoot, i+1, sw, baseCommit)
+		if err != nil {
+			return nil, err
+		}
+		m.Workers = append(m.Workers, wk)
 	}
 
 	fmt.Println("\n🚀  Launching tmux session…")
 
-	if err := tmux.NewSession(cfg.Session, worktreeDirs[0], 220, 50, "swarm"); err != nil {
-		return err
+	if err := tmux.NewSession(cfg.Session, m.Workers[0].Worktree, 220, 50, "swarm"); err != nil {
+		return nil, err
 	}
 
-	applyStatusBar(cfg, workers)
+	applyStatusBar(cfg, uniqueWorkerTypes(workers), len(workers))
 
-	paneIDs, err := setupSwarmWindow(cfg, workers, worktreeDirs)
-	if err != nil {
-		return err
+	if err := setupSwarmWindow(cfg, m.Workers); err != nil {
+		return nil, err
+	}
+	if err := state.Save(m); err != nil {
+		return nil, fmt.Errorf("saving session manifest: %w", err)
 	}
 
-	nvimID, lgID, err := setupHubWindow(cfg, repoRoot)
+	_, lgID, err := setupHubWindow(cfg, repoRoot)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	bindKeybindings(cfg, nvimID, lgID)
-
-	return runAndMonitor(cfg, repoRoot, workers, worktreeDirs, paneIDs, w)
+	bindKeybindings(cfg, lgID != "")
+	return m.Workers, nil
 }
 
-// createWorktrees creates git worktrees for all workers and returns their dirs.
-func createWorktrees(cfg *config.Config, repoRoot string, workers []string) ([]string, error) {
-	worktreeDirs := make([]string, len(workers))
-	for i := 1; i <= len(workers); i++ {
-		dir := wtDir(repoRoot, cfg.WorktreePrefix, i)
-		branch := wtBranch(cfg.BaseBranch, i)
-		_ = git.RemoveWorktree(dir)
-		_ = git.DeleteBranch(branch)
-		if err := git.AddWorktree(dir, branch, cfg.BaseBranch); err != nil {
-			return nil, err
-		}
-		worktreeDirs[i-1] = dir
-		fmt.Printf("✅  Worktree %d → %s  (branch: %s, CLI: %s)\n", i, dir, branch, workers[i-1])
+// createWorktree creates the git worktree for worker i and returns its manifest entry.
+func createWorktree(cfg *config.Config, repoRoot string, i int, sw spec.Worker, baseCommit string) (state.Worker, error) {
+	dir := wtDir(cfg, repoRoot, i)
+	if pattern, ok := wtExclude(cfg, repoRoot, dir); ok {
+		// Keep in-repo worktrees out of the main checkout's git status.
+		_ = git.AddExclude(pattern)
 	}
-	return worktreeDirs, nil
+	branch := wtBranch(cfg.Session, cfg.BaseBranch, i)
+	_ = git.RemoveWorktree(dir)
+	_ = git.DeleteBranch(branch)
+	if err := git.AddWorktree(dir, branch, baseCommit); err != nil {
+		return state.Worker{}, err
+	}
+	fmt.Printf("✅  Worktree %d → %s  (branch: %s, CLI: %s)\n", i, dir, branch, sw.Name())
+
+	prompt, err := spec.RenderPrompt(sw.Prompt, spec.PromptData{
+		Index:    i,
+		Session:  cfg.Session,
+		CLI:      sw.Name(),
+		Label:    sw.Label,
+		Branch:   branch,
+		Base:     cfg.BaseBranch,
+		Worktree: dir,
+	})
+	if err != nil {
+		return state.Worker{}, fmt.Errorf("worker %d: %w", i, err)
+	}
+
+	return state.Worker{
+		Index:      i,
+		CLI:        sw.CLI,
+		Model:      sw.Model,
+		Flags:      sw.Flags,
+		Env:        sw.Env,
+		Dir:        sw.Dir,
+		Label:      sw.Label,
+		Prompt:     prompt,
+		Worktree:   dir,
+		Branch:     branch,
+		BaseBranch: cfg.BaseBranch,
+		BaseCommit: baseCommit,
+	}, nil
 }
 
 // applyStatusBar sets session-scoped tmux status bar options in a deterministic order.
-func applyStatusBar(cfg *config.Config, workers []string) {
-	cliLabel := cfg.CLIType
-	if len(uniqueWorkerTypes(workers)) > 1 {
-		cliLabel = strings.Join(uniqueWorkerTypes(workers), ",")
-	}
+func applyStatusBar(cfg *config.Config, cliTypes []string, agents int) {
+	cliLabel := strings.Join(cliTypes, ",")
 	statusLeft := fmt.Sprintf(
 		"#[bg=colour33,fg=colour15,bold] 🤖 SWARM (%s) #[bg=colour235] ", cliLabel)
 	statusRight := fmt.Sprintf(
@@ -225,7 +497,7 @@ func applyStatusBar(cfg *config.Config, workers []string) {
 			"#[fg=colour39]Ctrl+b e#[fg=colour245]:editor  "+
 			"#[fg=colour39]Ctrl+b d#[fg=colour245]:detach  "+
 			"#[fg=colour196]Ctrl+Q#[fg=colour245]:quit",
-		len(workers))
+		agents)
 
 	statusOpts := [][2]string{
 		{"status", "on"},
@@ -247,42 +519,133 @@ func applyStatusBar(cfg *config.Config, workers []string) {
 	}
 }
 
-// setupSwarmWindow creates the 2×2 pane grid in the "swarm" window and
-// launches each AI CLI. Returns pane IDs (topLeft, topRight, bottomLeft, bottomRight).
-func setupSwarmWindow(cfg *config.Config, workers, worktreeDirs []string) ([]string, error) {
-	//
-	//  ┌─────────────┬─────────────┐
-	//  │   worker-1  │   worker-2  │
-	//  ├─────────────┼─────────────┤
-	//  │   worker-3  │   worker-4  │
-	//  └─────────────┴─────────────┘
-	//
-	topLeft, err := tmux.GetPaneID(fmt.Sprintf("%s:swarm", cfg.Session))
+// setupSwarmWindow creates one pane per worker across the "swarm", "swarm-2", …
+// windows, launches each AI CLI and records the pane IDs on the workers.
+func setupSwarmWindow(cfg *config.Config, workers []state.Worker) error {
+	first, err := tmux.GetPaneID(fmt.Sprintf("%s:%s", cfg.Session, layout.WindowName(0)))
 	if err != nil {
-		return nil, fmt.Errorf("getting initial pane ID: %w", err)
+		return fmt.Errorf("getting initial pane ID: %w", err)
 	}
-	topRight, err := tmux.SplitWindowGetPaneID(topLeft, worktreeDirs[1%len(workers)], 50, true)
-	if err != nil {
-		return nil, fmt.Errorf("creating top-right pane: %w", err)
+
+	paneIDs := make([]string, 0, len(workers))
+	idx := 0
+	for win, size := range layout.Plan(len(workers), cfg.MaxPanes) {
+		window := fmt.Sprintf("%s:%s", cfg.Session, layout.WindowName(win))
+		if win > 0 {
+			prev := fmt.Sprintf("%s:%s", cfg.Session, layout.WindowName(win-1))
+			first, err = tmux.NewWindowGetPaneID(prev, workers[idx].Worktree, layout.WindowName(win))
+			if err != nil {
+				return fmt.Errorf("creating window %s: %w", layout.WindowName(win), err)
+			}
+		}
+		paneIDs = append(paneIDs, first)
+		last := first
+		for k := 1; k < size; k++ {
+			paneID, err := splitAndReflow(cfg, window, last, workers[idx+k].Worktree)
+			if err != nil {
+				return fmt.Errorf("creating pane for worker %d: %w", idx+k+1, err)
+			}
+			paneIDs = append(paneIDs, paneID)
+			last = paneID
+		}
+		_ = tmux.SelectLayout(window, cfg.Layout)
+		idx += size
 	}
-	bottomLeft, err := tmux.SplitWindowGetPaneID(topLeft, worktreeDirs[2%len(workers)], 50, false)
-	if err != nil {
-		return nil, fmt.Errorf("creating bottom-left pane: %w", err)
+
+	for i, paneID := range paneIDs {
+		workers[i].PaneID = paneID
+		launchWorker(cfg, workers[i])
+	}
+	_ = tmux.SelectPane(paneIDs[0])
+
+	return nil
+}
+
+// launchWorker titles the worker's pane and starts its CLI inside the worktree
+// (or the worker's sub-directory of it).
+func launchWorker(cfg *config.Config, wk state.Worker) {
+	cmd := launchCmdFor(wk)
+	if cfg.PromptDelivery == promptKeys {
+		cmd = cliCmdFor(wk) // the prompt is typed in by deliverPrompts
+	}
+	_ = tmux.SetPaneTitle(wk.PaneID, paneTitle(wk))
+	_ = tmux.SendKeys(wk.PaneID, fmt.Sprintf("cd %s && %s", shellQuote(wk.StartDir()), cmd))
+}
+
+// Prompt delivery modes.
+const (
+	promptArg  = "arg"  // pass the prompt on the CLI's command line
+	promptKeys = "keys" // type it into the pane once the CLI is ready
+)
+
+// deliverPrompts types each worker's initial prompt into its pane once the
+// CLI shows its input prompt, reporting to w. It is a no-op unless
+// prompt_delivery is "keys".
+func deliverPrompts(cfg *config.Config, workers []state.Worker, w io.Writer) {
+	if cfg.PromptDelivery != promptKeys {
+		return
 	}
-	bottomRight, err := tmux.SplitWindowGetPaneID(topRight, worktreeDirs[3%len(workers)], 50, false)
+	var wg sync.WaitGroup
+	for _, wk := range workers {
+		if wk.Prompt == "" {
+			continue
+		}
+		fmt.Fprintf(w, "⏳  Waiting for worker-%d (%s) to be ready…\n", wk.Index, wk.CLI)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			timeout := time.Duration(cfg.PromptReadySecs) * time.Second
+			if !monitor.WaitReady(context.Background(), wk.PaneID, wk.CLI, timeout) {
+				fmt.Fprintf(w, "⚠️   worker-%d not ready after %s — prompt not sent.\n", wk.Index, timeout)
+				return
+			}
+			if err := tmux.SendText(wk.PaneID, wk.Prompt); err != nil {
+				fmt.Fprintf(w, "⚠️   worker-%d: %v\n", wk.Index, err)
+				return
+			}
+			fmt.Fprintf(w, "📨  Prompt sent to worker-%d.\n", wk.Index)
+		}()
+	}
+	wg.Wait()
+}
+
+// addSwarmPane creates a pane for one more worker: it splits the last swarm
+// window while it has room, otherwise opens the next "swarm-N" window.
+func addSwarmPane(cfg *config.Config, cwd string) (string, error) {
+	names, err := tmux.ListWindowNames(cfg.Session)
 	if err != nil {
-		return nil, fmt.Errorf("creating bottom-right pane: %w", err)
+		return "", err
+	}
+	lastWin := -1
+	for _, name := range names {
+		if n, ok := layout.WindowNumber(name); ok && n > lastWin {
+			lastWin = n
+		}
+	}
+	if lastWin < 0 {
+		return "", fmt.Errorf("no swarm window in session %q", cfg.Session)
 	}
 
-	workerPaneIDs := []string{topLeft, topRight, bottomLeft, bottomRight}
-	for i, paneID := range workerPaneIDs {
-		idx := i % len(workers)
-		_ = tmux.SetPaneTitle(paneID, paneTitle(i+1, workers[idx]))
-		_ = tmux.SendKeys(paneID, fmt.Sprintf("cd '%s' && %s", worktreeDirs[idx], cliCmdFor(cfg, workers[idx])))
+	window := fmt.Sprintf("%s:%s", cfg.Session, layout.WindowName(lastWin))
+	panes, err := tmux.ListPanes(window)
+	if err != nil {
+		return "", err
 	}
-	_ = tmux.SelectPane(topLeft)
+	if cfg.MaxPanes <= 0 || len(panes) < cfg.MaxPanes {
+		return splitAndReflow(cfg, window, panes[len(panes)-1], cwd)
+	}
+	return tmux.NewWindowGetPaneID(window, cwd, layout.WindowName(lastWin+1))
+}
 
-	return workerPaneIDs, nil
+// splitAndReflow splits paneID and rebalances its window so that the next
+// split always has room, keeping panes in worker order.
+func splitAndReflow(cfg *config.Config, window, paneID, cwd string) (string, error) {
+	newPane, err := tmux.SplitWindowGetPaneID(paneID, cwd, 50, false)
+	if err != nil {
+		return "", err
+	}
+	_ = tmux.SelectLayout(window, cfg.Layout)
+	return newPane, nil
 }
 
 // setupHubWindow creates the "hub" window with nvim and optionally lazygit.
@@ -314,50 +677,65 @@ func setupHubWindow(cfg *config.Config, repoRoot string) (nvimPaneID, lazygitPan
 	return
 }
 
-// bindKeybindings sets session-scoped tmux keybindings.
-func bindKeybindings(cfg *config.Config, hubPaneID, lazygitPaneID string) {
+// bindKeybindings installs the swarm's keybindings. Every binding resolves
+// its targets from the client's current session, and no-prefix keys only act
+// in sessions marked as swarms, so several swarms can run side by side
+// without overriding each other or the keys of other sessions.
+func bindKeybindings(cfg *config.Config, lazygit bool) {
+	_ = tmux.SetOption(cfg.Session, tmux.SwarmOption, "1")
+
 	// Alt+1 → swarm (agents), Alt+2 → hub
-	_ = tmux.BindKey(cfg.Session, "-n", "M-1",
-		fmt.Sprintf("select-window -t '%s:swarm'", cfg.Session))
-	_ = tmux.BindKey(cfg.Session, "-n", "M-2",
-		fmt.Sprintf("select-window -t '%s:hub'", cfg.Session))
+	_ = tmux.BindKey("-n", "M-1", "run-shell \"tmux select-window -t '#{session_name}:swarm'\"")
+	_ = tmux.BindKey("-n", "M-2", "run-shell \"tmux select-window -t '#{session_name}:hub'\"")
 
 	// Ctrl+b S → confirm then ship: open PR + cleanup for current worktree
-	_ = tmux.BindKey(cfg.Session, "", "S",
+	_ = tmux.BindKey("", "S",
 		"confirm-before -p \"Ship this worktree as a PR? (y/n)\" "+
 			"\"new-window -c '#{pane_current_path}' 'claude-swarm ship; echo; read -p \\\"Press Enter to close…\\\"'\"")
 
-	// Ctrl+Q → kill session (no prefix)
-	_ = tmux.BindKey(cfg.Session, "-n", "C-q",
-		fmt.Sprintf("kill-session -t '%s'", cfg.Session))
+	// Ctrl+Q → tear the swarm down (no prefix). detach-client -E runs
+	// "claude-swarm down" in the client's terminal so it can still prompt.
+	_ = tmux.BindKey("-n", "C-q",
+		"run-shell \"tmux detach-client -t '#{client_name}' -E \\\"claude-swarm down -s '#{session_name}'\\\"\"")
 
 	// Ctrl+b e → nvim, Ctrl+b g → lazygit
-	_ = tmux.BindKey(cfg.Session, "", "e",
-		fmt.Sprintf("run-shell \"tmux select-window -t '%s:hub' && tmux select-pane -t '%s'\"",
-			cfg.Session, hubPaneID))
-	if lazygitPaneID != "" {
-		_ = tmux.BindKey(cfg.Session, "", "g",
-			fmt.Sprintf("run-shell \"tmux select-window -t '%s:hub' && tmux select-pane -t '%s'\"",
-				cfg.Session, lazygitPaneID))
+	_ = tmux.BindKey("", "e",
+		"run-shell \"tmux select-window -t '#{session_name}:hub' && tmux select-pane -t '#{session_name}:hub.{left}'\"")
+	if lazygit {
+		_ = tmux.BindKey("", "g",
+			"run-shell \"tmux select-window -t '#{session_name}:hub' && tmux select-pane -t '#{session_name}:hub.{right}'\"")
 	}
 }
 
-// runAndMonitor attaches the tmux session, starts worker monitors, and handles post-detach cleanup.
-func runAndMonitor(cfg *config.Config, repoRoot string, workers, worktreeDirs, paneIDs []string, w io.Writer) error {
+// runAndMonitor attaches the tmux session and runs worker monitors while attached.
+// Detaching leaves the swarm running; cleanup is only offered once the session is gone.
+func runAndMonitor(cfg *config.Config, w io.Writer) error {
+	m, err := state.Load(cfg.Session)
+	if err != nil {
+		return err
+	}
 	_ = tmux.SelectWindow(fmt.Sprintf("%s:swarm", cfg.Session))
 
-	fmt.Printf("✅  All %d instances launched!\n", len(workers))
-	fmt.Printf("🔍  Monitors active (log: /tmp/claude-swarm-%s.log)\n", cfg.Session)
-	fmt.Printf("📎  Attaching to session %q…\n", cfg.Session)
-	fmt.Println("    Detach: Ctrl+b d  |  Hub: Alt+2  |  Agents: Alt+1")
-	fmt.Println()
+	if cfg.Daemon {
+		if err := startDaemon(cfg); err != nil {
+			fmt.Printf("⚠️   %v — monitoring in-process instead.\n", err)
+		}
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	for i, paneID := range paneIDs {
-		idx := i % len(workers)
-		go monitor.Watch(ctx, cfg, cfg.Session, paneID, i+1, cliCmdFor(cfg, workers[idx]), w)
+
+	fmt.Printf("✅  All %d instances running!\n", len(m.Workers))
+	if pid, ok := state.DaemonPid(cfg.Session); ok {
+		fmt.Printf("🔍  Monitors run in daemon pid %d (log: %s)\n", pid, logPath(cfg.Session))
+	} else if superviseInProcess(ctx, cfg, monitorLimits(cfg), w) {
+		fmt.Printf("🔍  Monitors active (log: %s)\n", logPath(cfg.Session))
+	} else {
+		fmt.Printf("🔍  Monitors run in another claude-swarm process (log: %s)\n", logPath(cfg.Session))
 	}
+	fmt.Printf("📎  Attaching to session %q…\n", cfg.Session)
+	fmt.Println("    Detach: Ctrl+b d  |  Hub: Alt+2  |  Agents: Alt+1")
+	fmt.Println()
 
 	attachCmd := exec.Command("tmux", "attach-session", "-t", cfg.Session)
 	attachCmd.Stdin = os.Stdin
@@ -365,81 +743,112 @@ func runAndMonitor(cfg *config.Config, repoRoot string, workers, worktreeDirs, p
 	attachCmd.Stderr = os.Stderr
 	_ = attachCmd.Run()
 
-	fmt.Println("\n🔴  Stopping monitors…")
+	if _, ok := state.DaemonPid(cfg.Session); !ok {
+		fmt.Println("\n🔴  Stopping monitors…")
+	}
 	cancel()
 
-	return postDetachCleanup(cfg, repoRoot, worktreeDirs)
+	if tmux.HasSession(cfg.Session) {
+		fmt.Printf("ℹ️   Swarm still running. Re-attach with: claude-swarm attach -s %s\n", cfg.Session)
+		return nil
+	}
+
+	// Ctrl+Q tears down through "claude-swarm down", which clears the pane
+	// IDs. If they are still recorded the session died some other way.
+	if m, err := state.Load(cfg.Session); err == nil {
+		for _, wk := range m.Workers {
+			if wk.PaneID != "" {
+				return runDown(cfg, cfg.DownPolicy)
+			}
+		}
+	}
+	return nil
 }
 
 // ── Add-mode ──────────────────────────────────────────────────────────────────
 
-func addWorkers(cfg *config.Config, repoRoot string, workers []string) error {
+// addWorkers starts workers in a running session, giving them the smallest
+// free indices, and returns them for deliverPrompts. The caller must hold the
+// session lock.
+func addWorkers(cfg *config.Config, repoRoot string, workers []spec.Worker) ([]state.Worker, error) {
 	if !tmux.HasSession(cfg.Session) {
-		return fmt.Errorf("session %q not found — start a swarm first (without -a)", cfg.Session)
+		return nil, fmt.Errorf("session %q not found — start a swarm first (without -a)", cfg.Session)
 	}
-
-	// Count existing worker panes by looking at pane titles in the swarm window.
-	// Simpler: just check how many worktree dirs exist already.
-	i := 1
-	for {
-		if _, err := os.Stat(wtDir(repoRoot, cfg.WorktreePrefix, i)); os.IsNotExist(err) {
-			break
-		}
-		i++
+	m, err := state.Load(cfg.Session)
+	if err != nil {
+		return nil, fmt.Errorf("session %q has no manifest — restart it without -a: %w", cfg.Session, err)
+	}
+	baseCommit, err := git.RevParse(cfg.BaseBranch)
+	if err != nil {
+		return nil, err
 	}
-	startIdx := i
 
-	for j, cliType := range workers {
-		i := startIdx + j
-		dir := wtDir(repoRoot, cfg.WorktreePrefix, i)
-		branch := wtBranch(cfg.BaseBranch, i)
-		_ = git.RemoveWorktree(dir)
-		_ = git.DeleteBranch(branch)
-		if err := git.AddWorktree(dir, branch, cfg.BaseBranch); err != nil {
-			return err
+	var added []state.Worker
+	for j, i := range m.FreeIndices(len(workers)) {
+		sw := workers[j]
+		wk, err := createWorktree(cfg, repoRoot, i, sw, baseCommit)
+		if err != nil {
+			return nil, err
 		}
-		fmt.Printf("✅  Worktree %d → %s  (branch: %s, CLI: %s)\n", i, dir, branch, cliType)
 
-		// Find the last pane in swarm window and split it.
-		newPane, err := tmux.SplitWindowGetPaneID(fmt.Sprintf("%s:swarm", cfg.Session), dir, 50, false)
+		wk.PaneID, err = addSwarmPane(cfg, wk.Worktree)
 		if err != nil {
-			return fmt.Errorf("creating pane for worker %d: %w", i, err)
+			return nil, fmt.Errorf("creating pane for worker %d: %w", i, err)
+		}
+		launchWorker(cfg, wk)
+
+		m.Workers = append(m.Workers, wk)
+		added = append(added, wk)
+		if err := state.Save(m); err != nil {
+			return nil, fmt.Errorf("saving session manifest: %w", err)
 		}
-		_ = tmux.SetPaneTitle(newPane, paneTitle(i, cliType))
-		_ = tmux.SendKeys(newPane, fmt.Sprintf("cd '%s' && %s", dir, cliCmdFor(cfg, cliType)))
 	}
 
 	fmt.Printf("✅  Added %d worker(s) to session %q.\n", len(workers), cfg.Session)
-	return nil
+	return added, nil
 }
 
-// ── Cleanup ───────────────────────────────────────────────────────────────────
+// superviseInProcess runs the session's monitors in this process until ctx
+// ends, unless another process — the daemon, or another attach or run —
+// already does, so every limit, stall and exit is acted on once. It reports
+// whether it started them.
+func superviseInProcess(ctx context.Context, cfg *config.Config, limits *usagelimit.Parser, w io.Writer) bool {
+	unlock, ok, err := state.TryLock(state.MonitorLock(cfg.Session))
+	if err != nil || !ok {
+		return false
+	}
+	go func() {
+		defer unlock()
+		monitor.Supervise(ctx, cfg, limits, cfg.Session, monitorTargets(cfg.Session), w)
+	}()
+	return true
+}
 
-func postDetachCleanup(cfg *config.Config, repoRoot string, worktreeDirs []string) error {
-	fmt.Print("\n🧹  Remove worktrees and swarm branches? [Y/n] ")
-	reader := bufio.NewReader(os.Stdin)
-	answer, _ := reader.ReadString('\n')
-	answer = strings.TrimSpace(answer)
-	if answer == "" {
-		answer = "Y"
-	}
-	if strings.EqualFold(answer, "y") {
-		for _, dir := range worktreeDirs {
-			branch, _ := git.BranchOfWorktree(dir)
-			_ = git.RemoveWorktree(dir)
-			if branch != "" {
-				_ = git.DeleteBranch(branch)
-			}
-		}
-		_ = git.Prune()
-		fmt.Println("✅  Cleaned up.")
-	} else {
-		fmt.Println("ℹ️   Worktrees kept. Remove manually with: git worktree remove <path>")
+// lockSession takes the session lock, telling the user when it has to wait.
+func lockSession(session string) (func(), error) {
+	return state.Lock(session, func() {
+		fmt.Printf("⏳  Waiting for another claude-swarm command on %q…\n", session)
+	})
+}
+
+// updateManifest reloads the session manifest under the session lock, applies
+// fn and saves it, so changes other commands saved meanwhile are kept.
+func updateManifest(session string, fn func(m *state.Manifest)) error {
+	unlock, err := lockSession(session)
+	if err != nil {
+		return err
 	}
-	_ = repoRoot
-	return nil
+	defer unlock()
+	m, err := state.Load(session)
+	if err != nil {
+		return err
+	}
+	fn(m)
+	return state.Save(m)
 }
 
+// ── Cleanup ───────────────────────────────────────────────────────────────────
+
 func commandExists(name string) bool {
 	_, err := exec.LookPath(name)
 	return err == nil
@@ -476,22 +885,50 @@ func parseCLITypes(raw string) []string {
 	return cliTypes
 }
 
-func buildWorkers(cfg *config.Config) []string {
-	cliTypes := parseCLITypes(cfg.CLIType)
-	workers := make([]string, cfg.Num)
-	for i := 0; i < cfg.Num; i++ {
-		workers[i] = cliTypes[i%len(cliTypes)]
+// buildWorkers returns the workers to launch: those of the spec file when one
+// is in use, otherwise cfg.Num workers round-robining the -t CLI list.
+func buildWorkers(cfg *config.Config) ([]spec.Worker, error) {
+	var workers []spec.Worker
+	if cfg.Spec != "" {
+		sp, err := spec.Load(cfg.Spec)
+		if err != nil {
+			return nil, err
+		}
+		workers = sp.Expand()
+	} else {
+		if cfg.Num < 1 {
+			return nil, fmt.Errorf("-n must be a positive integer")
+		}
+		cliTypes := parseCLITypes(cfg.CLIType)
+		if len(cliTypes) == 0 {
+			return nil, nil
+		}
+		workers = make([]spec.Worker, cfg.Num)
+		for i := 0; i < cfg.Num; i++ {
+			cliName, model := parseWorker(cliTypes[i%len(cliTypes)])
+			workers[i] = spec.Worker{CLI: cliName, Model: model, Flags: cfg.CLIFlags}
+		}
 	}
-	return workers
+
+	if cfg.Tasks != "" {
+		tasks, err := spec.LoadTasks(cfg.Tasks)
+		if err != nil {
+			return nil, err
+		}
+		if err := spec.AssignTasks(workers, tasks); err != nil {
+			return nil, err
+		}
+	}
+	return workers, nil
 }
 
-func normalizeWorkers(workers []string) []string {
+func normalizeWorkers(workers []spec.Worker) []spec.Worker {
 	workers = normalizeGemini(workers)
 	workers = normalizeCodex(workers)
 	return workers
 }
 
-func normalizeGemini(workers []string) []string {
+func normalizeGemini(workers []spec.Worker) []spec.Worker {
 	if !containsCLIType(workers, "gemini") {
 		return workers
 	}
@@ -504,23 +941,13 @@ func normalizeGemini(workers []string) []string {
 		fmt.Println("⚠️   No fallback CLI (claude/codex) was found, keeping gemini workers as-is.")
 		return workers
 	}
-	replaced := make([]string, len(workers))
-	replacedCount := 0
-	for i, cliType := range workers {
-		cliName, _ := parseWorker(cliType)
-		if cliName == "gemini" {
-			replaced[i] = fallback
-			replacedCount++
-		} else {
-			replaced[i] = cliType
-		}
-	}
+	replaced, replacedCount := replaceCLI(workers, "gemini", fallback)
 	fmt.Printf("⚠️   Gemini failed health check; replaced %d worker(s) with %s.\n", replacedCount, fallback)
 	fmt.Println("⚠️   Fix locally by upgrading Node.js and reinstalling @google/gemini-cli.")
 	return replaced
 }
 
-func normalizeCodex(workers []string) []string {
+func normalizeCodex(workers []spec.Worker) []spec.Worker {
 	if !containsCLIType(workers, "codex") {
 		return workers
 	}
@@ -533,25 +960,29 @@ func normalizeCodex(workers []string) []string {
 		fmt.Println("⚠️   No fallback CLI (claude/gemini) was found, keeping codex workers as-is.")
 		return workers
 	}
-	replaced := make([]string, len(workers))
+	replaced, replacedCount := replaceCLI(workers, "codex", fallback)
+	fmt.Printf("⚠️   Codex failed health check; replaced %d worker(s) with %s.\n", replacedCount, fallback)
+	return replaced
+}
+
+// replaceCLI swaps every worker using cliType for fallback. Model and flags
+// belong to the old CLI, so they are dropped; everything else is kept.
+func replaceCLI(workers []spec.Worker, cliType, fallback string) ([]spec.Worker, int) {
+	replaced := make([]spec.Worker, len(workers))
 	replacedCount := 0
-	for i, cliType := range workers {
-		cliName, _ := parseWorker(cliType)
-		if cliName == "codex" {
-			replaced[i] = fallback
+	for i, wk := range workers {
+		if wk.CLI == cliType {
+			wk.CLI, wk.Model, wk.Flags = fallback, "", ""
 			replacedCount++
-		} else {
-			replaced[i] = cliType
 		}
+		replaced[i] = wk
 	}
-	fmt.Printf("⚠️   Codex failed health check; replaced %d worker(s) with %s.\n", replacedCount, fallback)
-	return replaced
+	return replaced, replacedCount
 }
 
-func containsCLIType(workers []string, cliType string) bool {
+func containsCLIType(workers []spec.Worker, cliType string) bool {
 	for _, worker := range workers {
-		cliName, _ := parseWorker(worker)
-		if cliName == cliType {
+		if worker.CLI == cliType {
 			return true
 		}
 	}
@@ -593,28 +1024,82 @@ func geminiHealthCheck() bool {
 	return false
 }
 
-func uniqueWorkerTypes(workers []string) []string {
+func uniqueWorkerTypes(workers []spec.Worker) []string {
 	seen := make(map[string]bool, len(workers))
 	ordered := make([]string, 0, len(workers))
 	for _, worker := range workers {
-		if !seen[worker] {
-			seen[worker] = true
-			ordered = append(ordered, worker)
+		if name := worker.Name(); !seen[name] {
+			seen[name] = true
+			ordered = append(ordered, name)
 		}
 	}
 	return ordered
 }
 
-// cliCmdFor returns the full CLI invocation for a worker, including model and extra flags.
-// Worker may be "gemini:gemini-2.0-flash" or plain "claude".
-func cliCmdFor(cfg *config.Config, worker string) string {
-	cliName, model := parseWorker(worker)
-	cmd := cliName
-	if model != "" {
-		cmd += " --model " + model
+// cliCmdFor returns the full CLI invocation for a worker, including its
+// environment, model and extra flags.
+func cliCmdFor(wk state.Worker) string {
+	var cmd strings.Builder
+	keys := make([]string, 0, len(wk.Env))
+	for k := range wk.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&cmd, "%s=%s ", k, shellQuote(wk.Env[k]))
+	}
+	cmd.WriteString(wk.CLI)
+	if wk.Model != "" {
+		cmd.WriteString(" --model " + wk.Model)
+	}
+	if wk.Flags != "" {
+		cmd.WriteString(" " + wk.Flags)
+	}
+	return cmd.String()
+}
+
+// resumeArgs reopen the CLI's most recent conversation in the current
+// directory.
+var resumeArgs = map[string]string{
+	"claude": "--continue",
+	"gemini": "--resume latest",
+	"codex":  "resume --last",
+}
+
+// resumeCmdFor returns the command that relaunches a worker's CLI on its last
+// conversation, from the worker's start directory.
+func resumeCmdFor(wk state.Worker) string {
+	args := resumeArgs[wk.CLI]
+	if wk.CLI == "codex" {
+		wk.CLI += " " + args // a subcommand, so it goes before the flags
+		args = ""
 	}
-	if cfg.CLIFlags != "" {
-		cmd += " " + cfg.CLIFlags
+	cmd := cliCmdFor(wk)
+	if args != "" {
+		cmd += " " + args
 	}
-	return cmd
+	return fmt.Sprintf("cd %s && %s", shellQuote(wk.StartDir()), cmd)
+}
+
+// launchCmdFor returns the command that first starts a worker: its CLI
+// invocation plus the initial prompt, if any.
+func launchCmdFor(wk state.Worker) string {
+	if wk.Prompt == "" {
+		return cliCmdFor(wk)
+	}
+	return cliCmdFor(wk) + promptArgs(wk.CLI, wk.Prompt)
+}
+
+// promptArgs returns the arguments that start a CLI's interactive session
+// with an initial prompt: gemini takes it via -i, claude and codex positionally.
+func promptArgs(cliName, prompt string) string {
+	if cliName == "gemini" {
+		return " -i " + shellQuote(prompt)
+	}
+	return " " + shellQuote(prompt)
+}
+
+// shellQuote single-quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
@@ -0,0 +1,422 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/cpoulin/claude-swarm/internal/config"
+	"github.com/cpoulin/claude-swarm/internal/git"
+	"github.com/cpoulin/claude-swarm/internal/monitor"
+	"github.com/cpoulin/claude-swarm/internal/queue"
+	"github.com/cpoulin/claude-swarm/internal/state"
+	"github.com/cpoulin/claude-swarm/internal/tmux"
+	"github.com/cpoulin/claude-swarm/internal/usagelimit"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// sentinelFile is created in the worktree root by an agent that has finished its task.
+const sentinelFile = ".swarm-done"
+
+// Ways a task can be detected as finished.
+const (
+	doneSentinel = "sentinel"
+	doneCommit   = "commit"
+	doneIdle     = "idle"
+)
+
+// queuePoll is how often the dispatcher looks at each busy worker.
+const queuePoll = 5 * time.Second
+
+// clearCmd is the slash command each CLI uses to start a fresh conversation.
+var clearCmd = map[string]string{
+	"claude": "/clear",
+	"gemini": "/clear",
+	"codex":  "/new",
+}
+
+var runCmd = &cobra.Command{
+	Use:   "run --queue tasks.md",
+	Short: "Feed a markdown backlog to the swarm's workers, one task at a time",
+	Long: `Dispatches tasks from a markdown file ("- [ ] task" checklist items, or one
+task per "##" section) to idle workers. Each task runs on its own branch,
+swarm/<session>/<base>/task-K, started from the latest base branch; a task
+is finished when the agent creates .swarm-done, commits and goes quiet, or
+sits idle at its prompt (see --done-on).
+
+Progress is kept in .git/claude-swarm/<session>.queue.json: run the same
+command again after a restart and it continues where it stopped. Tasks that
+were running in panes that no longer exist are queued again.
+
+The swarm is started detached when the session is not running.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if cmd.Flags().Changed("num") {
+			cfg.Num, _ = cmd.Flags().GetInt("num")
+		}
+		if cmd.Flags().Changed("type") {
+			cfg.CLIType, _ = cmd.Flags().GetString("type")
+		}
+		if err := resolveSpec(cmd, cfg); err != nil {
+			return err
+		}
+		path, _ := cmd.Flags().GetString("queue")
+		return runQueue(cfg, path)
+	},
+}
+
+func init() {
+	f := runCmd.Flags()
+	f.String("queue", "", "Markdown file with the tasks to dispatch")
+	f.IntP("num", "n", 0, "Number of workers when the swarm has to be started (default: 4)")
+	f.StringP("type", "t", "", "AI CLI(s) when the swarm has to be started")
+	f.String("done-on", "", "How finished tasks are detected: comma list of sentinel, commit, idle (default: all)")
+	f.Int("idle", 0, "Seconds at the input prompt before a worker counts as idle (default: 120)")
+	f.Bool("clear", true, "Start each task in a fresh conversation (/clear)")
+	_ = runCmd.MarkFlagRequired("queue")
+	_ = viper.BindPFlag("queue_done_on", f.Lookup("done-on"))
+	_ = viper.BindPFlag("queue_idle_secs", f.Lookup("idle"))
+	_ = viper.BindPFlag("queue_clear", f.Lookup("clear"))
+	rootCmd.AddCommand(runCmd)
+}
+
+// taskBranch returns the branch a queued task runs on.
+func taskBranch(session, baseBranch string, num int) string {
+	return fmt.Sprintf("swarm/%s/%s/task-%d", session, baseBranch, num)
+}
+
+// dispatcher hands queued tasks to the workers of one session.
+type dispatcher struct {
+	cfg    *config.Config
+	limits *usagelimit.Parser
+	q      *queue.Queue
+	doneOn map[string]bool
+	idle   time.Duration
+	w      io.Writer
+
+	mu sync.Mutex // guards q and its file; the manifest is under the session lock
+}
+
+func runQueue(cfg *config.Config, path string) error {
+	doneOn := map[string]bool{}
+	for _, d := range strings.Split(cfg.QueueDoneOn, ",") {
+		d = strings.TrimSpace(d)
+		switch d {
+		case doneSentinel, doneCommit, doneIdle:
+			doneOn[d] = true
+		case "":
+		default:
+			return fmt.Errorf("unknown --done-on %q — use sentinel, commit or idle", d)
+		}
+	}
+	if len(doneOn) == 0 {
+		return fmt.Errorf("--done-on needs at least one of sentinel, commit or idle")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("reading queue: %w", err)
+	}
+	tasks, err := queue.Parse(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("parsing queue %s: %w", path, err)
+	}
+
+	if !tmux.HasSession(cfg.Session) {
+		workers, repoRoot, err := planSwarm(cfg)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("📺  Starting session %q with %d workers…\n", cfg.Session, len(workers))
+		started, err := launchSwarm(cfg, repoRoot, workers)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("📎  Attach from another terminal with: claude-swarm attach -s %s\n", cfg.Session)
+		// Workers busy with their initial prompt get no task before it is in.
+		deliverPrompts(cfg, started, os.Stdout)
+	}
+	m, err := state.Load(cfg.Session)
+	if err != nil {
+		return fmt.Errorf("session %q has no manifest — restart it: %w", cfg.Session, err)
+	}
+
+	q, err := queue.Load(cfg.Session)
+	if err != nil {
+		return err
+	}
+	q.Source, _ = filepath.Abs(path)
+	q.Merge(tasks)
+	sameSwarm := q.SwarmCreatedAt.Equal(m.CreatedAt)
+	q.SwarmCreatedAt = m.CreatedAt
+	if n := q.Requeue(func(worker int) string {
+		if wk := m.Worker(worker); wk != nil && sameSwarm {
+			return wk.PaneID
+		}
+		return ""
+	}); n > 0 {
+		fmt.Printf("♻️   %d task(s) were running in panes that are gone — queued again.\n", n)
+	}
+	if err := queue.Save(q); err != nil {
+		return err
+	}
+	_ = git.AddExclude("/" + sentinelFile)
+
+	w, closeLog := openLog(cfg.Session)
+	defer closeLog()
+
+	pending, running, done := q.Counts()
+	fmt.Printf("📋  Queue   : %s  (%d pending, %d running, %d done)\n", path, pending, running, done)
+	if pending+running == 0 {
+		fmt.Println("✅  Nothing left to do.")
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	limits := monitorLimits(cfg)
+	if _, ok := state.DaemonPid(cfg.Session); !ok {
+		superviseInProcess(ctx, cfg, limits, w)
+	}
+	fmt.Println("    Stop with Ctrl+C; run the same command again to continue.")
+	fmt.Println()
+
+	d := &dispatcher{
+		cfg:    cfg,
+		limits: limits,
+		q:      q,
+		doneOn: doneOn,
+		idle:   time.Duration(cfg.QueueIdleSecs) * time.Second,
+		w:      w,
+	}
+	var wg sync.WaitGroup
+	for _, wk := range m.Workers {
+		if wk.PaneID == "" {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := d.work(ctx, wk); err != nil && ctx.Err() == nil {
+				d.logf("[worker-%d] %v — no more tasks for it.", wk.Index, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		fmt.Println("\n⏸   Dispatcher stopped — progress saved.")
+		return nil
+	}
+	d.printSummary()
+	return nil
+}
+
+// work runs tasks on one worker until the queue is empty, ctx is cancelled or
+// the worker's pane goes away.
+func (d *dispatcher) work(ctx context.Context, wk state.Worker) error {
+	for {
+		d.mu.Lock()
+		t := d.q.RunningOn(wk.Index)
+		d.mu.Unlock()
+
+		if t == nil {
+			if !d.waitIdle(ctx, wk) {
+				return ctx.Err()
+			}
+			d.mu.Lock()
+			t = d.q.Claim(wk.Index, wk.PaneID)
+			var err error
+			if t != nil {
+				err = queue.Save(d.q)
+			}
+			d.mu.Unlock()
+			if t == nil {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := d.start(ctx, &wk, t.ID); err != nil {
+				return err
+			}
+		} else {
+			d.logf("[worker-%d] still on task %d — watching it again.", wk.Index, t.Num)
+		}
+
+		by, err := d.watch(ctx, wk, t.ID)
+		if err != nil {
+			return err
+		}
+		_ = os.Remove(filepath.Join(wk.Worktree, sentinelFile))
+		d.mu.Lock()
+		d.q.Complete(t.ID, by)
+		task := *d.q.Task(t.ID)
+		err = queue.Save(d.q)
+		pending, running, done := d.q.Counts()
+		d.mu.Unlock()
+		d.logf("[worker-%d] finished task %d (%s, %s) — %d pending, %d running, %d done.", wk.Index, task.Num, by, task.Branch, pending, running, done)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// waitIdle blocks until the worker sits at its input prompt.
+func (d *dispatcher) waitIdle(ctx context.Context, wk state.Worker) bool {
+	for ctx.Err() == nil {
+		if monitor.WaitReady(ctx, wk.PaneID, wk.CLI, time.Minute) {
+			return true
+		}
+	}
+	return false
+}
+
+// start moves the worker onto the task's branch and sends it the prompt.
+// Leftover changes from the previous task are committed to that task's branch
+// first, so nothing is lost.
+func (d *dispatcher) start(ctx context.Context, wk *state.Worker, id string) error {
+	d.mu.Lock()
+	t := *d.q.Task(id)
+	d.mu.Unlock()
+
+	_ = os.Remove(filepath.Join(wk.Worktree, sentinelFile))
+	if n, err := git.DirtyFiles(wk.Worktree); err == nil && n > 0 {
+		msg := fmt.Sprintf("claude-swarm: uncommitted work left on %s", wk.Branch)
+		if err := git.CommitAll(wk.Worktree, msg); err != nil {
+			return fmt.Errorf("committing leftover changes: %w", err)
+		}
+		d.logf("[worker-%d] committed %d leftover change(s) on %s.", wk.Index, n, wk.Branch)
+	}
+
+	branch := taskBranch(d.cfg.Session, wk.BaseBranch, t.Num)
+	base, err := git.RevParse(wk.BaseBranch)
+	if err != nil {
+		return err
+	}
+	if err := git.SwitchBranch(wk.Worktree, branch, base); err != nil {
+		return err
+	}
+	head, err := git.RevParse("refs/heads/" + branch)
+	if err != nil {
+		return err
+	}
+	wk.SwitchBranch(branch, base)
+
+	d.mu.Lock()
+	if qt := d.q.Task(id); qt != nil {
+		qt.Branch, qt.StartCommit = branch, head
+	}
+	err = queue.Save(d.q)
+	d.mu.Unlock()
+	_ = updateManifest(d.cfg.Session, func(m *state.Manifest) {
+		if mw := m.Worker(wk.Index); mw != nil {
+			mw.SwitchBranch(branch, base)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if c, ok := clearCmd[wk.CLI]; ok && d.cfg.QueueClear {
+		if err := tmux.SendKeys(wk.PaneID, c); err != nil {
+			return err
+		}
+		time.Sleep(time.Second)
+		d.waitIdle(ctx, *wk)
+	}
+
+	prompt := t.Prompt
+	if d.doneOn[doneSentinel] {
+		prompt += fmt.Sprintf("\n\nWhen the task is complete, commit your work and create an empty file %s.",
+			filepath.Join(wk.Worktree, sentinelFile))
+	}
+	if err := tmux.SendText(wk.PaneID, prompt); err != nil {
+		return err
+	}
+	d.logf("[worker-%d] started task %d on %s: %s", wk.Index, t.Num, branch, t.Title)
+	return nil
+}
+
+// watch polls the worker until its task is finished and reports how that was
+// detected. A worker stuck on a usage limit is never taken for idle.
+func (d *dispatcher) watch(ctx context.Context, wk state.Worker, id string) (string, error) {
+	d.mu.Lock()
+	t := *d.q.Task(id)
+	d.mu.Unlock()
+
+	last, changed := "", time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(queuePoll):
+		}
+
+		if d.doneOn[doneSentinel] {
+			if _, err := os.Stat(filepath.Join(wk.Worktree, sentinelFile)); err == nil {
+				return doneSentinel, nil
+			}
+		}
+
+		if monitor.IsPaused(wk.PaneID) {
+			changed = time.Now() // a frozen screen is not a quiet one
+			continue
+		}
+		content, history, err := tmux.CapturePaneStyled(wk.PaneID)
+		if err != nil {
+			return "", fmt.Errorf("pane %s is gone", wk.PaneID)
+		}
+		if content != last {
+			last, changed = content, time.Now()
+			continue
+		}
+		command, _ := tmux.DisplayFormat(wk.PaneID, "#{pane_current_command}")
+		if s, _ := monitor.ClassifyPane(d.limits, wk.PaneID, wk.CLI, content, history, command); s != monitor.Idle {
+			continue
+		}
+		quiet := time.Since(changed)
+
+		if d.doneOn[doneCommit] && t.StartCommit != "" && quiet >= 2*queuePoll {
+			if n, err := git.CountCommits(t.Branch, t.StartCommit); err == nil && n > 0 {
+				return doneCommit, nil
+			}
+		}
+		if d.doneOn[doneIdle] && quiet >= d.idle {
+			return doneIdle, nil
+		}
+	}
+}
+
+func (d *dispatcher) logf(format string, args ...any) {
+	fmt.Fprintf(d.w, time.Now().UTC().Format("2006-01-02T15:04:05Z")+" "+format+"\n", args...)
+}
+
+// printSummary lists the branch of every finished task.
+func (d *dispatcher) printSummary() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	fmt.Println("\n🏁  Queue finished:")
+	for _, t := range d.q.Tasks {
+		if t.Status != queue.Done {
+			continue
+		}
+		branch := t.Branch
+		if branch == "" {
+			branch = "(checked off in the file)"
+		}
+		fmt.Printf("    task %-3d %-40s %s\n", t.Num, branch, t.Title)
+	}
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/cpoulin/claude-swarm/internal/config"
+	"github.com/cpoulin/claude-swarm/internal/layout"
+	"github.com/cpoulin/claude-swarm/internal/spec"
+	"github.com/cpoulin/claude-swarm/internal/state"
+	"github.com/cpoulin/claude-swarm/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var scaleCmd = &cobra.Command{
+	Use:   "scale N",
+	Short: "Add or remove workers until exactly N are running",
+	Long: `Grows or shrinks a running swarm to N workers.
+
+New workers take the smallest free worker numbers, so gaps left by removed
+workers are filled first; their CLI is the one the spec or -t list gives that
+worker number. Shrinking retires the highest-numbered workers first, with the
+same safe cleanup as rm. Panes are reflowed afterwards. Concurrent scale, rm,
+swap and -a commands on the same session wait for each other.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runScale,
+}
+
+func init() {
+	f := scaleCmd.Flags()
+	f.StringP("type", "t", "", "AI CLI(s) for new workers: claude|gemini|codex (or comma list)")
+	f.Bool(policyKeep, false, "Keep the worktrees and branches of removed workers")
+	f.BoolP("yes", "y", false, "Do not ask; also remove unmerged, unpushed or dirty worktrees")
+	rootCmd.AddCommand(scaleCmd)
+}
+
+func runScale(cmd *cobra.Command, args []string) error {
+	target, err := strconv.Atoi(args[0])
+	if err != nil || target < 1 {
+		return fmt.Errorf("N must be a positive number of workers (use down to stop the swarm)")
+	}
+	keep, _ := cmd.Flags().GetBool(policyKeep)
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("type") {
+		cfg.CLIType, _ = cmd.Flags().GetString("type")
+	}
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		cfg.Force = true
+	}
+	if err := resolveSpec(cmd, cfg); err != nil {
+		return err
+	}
+
+	if !tmux.HasSession(cfg.Session) {
+		return fmt.Errorf("session %q is not running", cfg.Session)
+	}
+	added, err := scaleTo(cfg, target, keep)
+	if err != nil {
+		return err
+	}
+	deliverPrompts(cfg, added, os.Stdout)
+	return nil
+}
+
+// scaleTo adds or retires workers under the session lock and returns the
+// added ones, whose prompts are delivered after the lock is released.
+func scaleTo(cfg *config.Config, target int, keep bool) ([]state.Worker, error) {
+	unlock, err := lockSession(cfg.Session)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	m, err := state.Load(cfg.Session)
+	if err != nil {
+		return nil, err
+	}
+	var added []state.Worker
+	var running []int
+	for _, wk := range m.Workers {
+		if wk.PaneID != "" {
+			running = append(running, wk.Index)
+		}
+	}
+
+	switch {
+	case target > len(running):
+		indices := m.FreeIndices(target - len(running))
+		workers, repoRoot, err := workersAt(cfg, indices)
+		if err != nil {
+			return nil, err
+		}
+		if added, err = addWorkers(cfg, repoRoot, workers); err != nil {
+			return nil, err
+		}
+	case target < len(running):
+		stdin := bufio.NewReader(os.Stdin)
+		for k := len(running) - 1; k >= target; k-- {
+			if err := retireWorker(cfg, m, running[k], keep, stdin); err != nil {
+				return nil, err
+			}
+		}
+		reflowSwarm(cfg)
+	default:
+		fmt.Printf("ℹ️   Session %q already runs %d worker(s).\n", cfg.Session, target)
+		return nil, nil
+	}
+	fmt.Printf("✅  Session %q now runs %d worker(s).\n", cfg.Session, target)
+	return added, nil
+}
+
+// workersAt returns the workers to start at the given indices: worker i gets
+// what a fresh swarm would give worker i, from the spec or the -t list. The
+// base branch is the one the running swarm uses.
+func workersAt(cfg *config.Config, indices []int) ([]spec.Worker, string, error) {
+	cfg.AddMode = true
+	cfg.Num = indices[len(indices)-1]
+	all, repoRoot, err := planSwarm(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(all) == 0 {
+		return nil, "", fmt.Errorf("no CLI available for new workers")
+	}
+	workers := make([]spec.Worker, len(indices))
+	for j, i := range indices {
+		workers[j] = all[(i-1)%len(all)]
+	}
+	return workers, repoRoot, nil
+}
+
+// reflowSwarm reapplies the layout to every swarm window.
+func reflowSwarm(cfg *config.Config) {
+	names, err := tmux.ListWindowNames(cfg.Session)
+	if err != nil {
+		return
+	}
+	for _, name := range names {
+		if _, ok := layout.WindowNumber(name); ok {
+			_ = tmux.SelectLayout(fmt.Sprintf("%s:%s", cfg.Session, name), cfg.Layout)
+		}
+	}
+}
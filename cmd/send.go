@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/cpoulin/claude-swarm/internal/monitor"
+	"github.com/cpoulin/claude-swarm/internal/state"
+	"github.com/cpoulin/claude-swarm/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var sendCmd = &cobra.Command{
+	Use:   "send -w N [message | -]",
+	Short: "Send a message to one or more workers",
+	Long: `Types a message into worker panes and submits it. The message is taken
+from the arguments, from --file, or from stdin ("-", or when stdin is piped).
+
+Workers that are not idle at their prompt are skipped unless --busy is given;
+workers stopped by a usage limit, paused, or whose CLI has exited are always
+skipped.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		indices, _ := cmd.Flags().GetIntSlice("worker")
+		if len(indices) == 0 {
+			return fmt.Errorf("pick the worker(s) with -w, or use broadcast")
+		}
+		return runSend(cmd, args, indices)
+	},
+}
+
+var broadcastCmd = &cobra.Command{
+	Use:   "broadcast [message | -]",
+	Short: "Send a message to every worker (optionally only those of some CLIs)",
+	Long: `Like send, for all workers of the session. --cli limits it to workers
+running the given CLIs.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSend(cmd, args, nil)
+	},
+}
+
+func init() {
+	sendCmd.Flags().IntSliceP("worker", "w", nil, "Worker number(s), e.g. -w 2 or -w 2,3")
+	for _, c := range []*cobra.Command{sendCmd, broadcastCmd} {
+		c.Flags().StringP("file", "f", "", "Read the message from a file")
+		c.Flags().String("cli", "", "Only workers running these CLIs (comma list, e.g. claude,codex)")
+		c.Flags().Bool("busy", false, "Also send to workers that are busy or waiting for an answer")
+		rootCmd.AddCommand(c)
+	}
+}
+
+// runSend delivers the message to the selected workers: the given indices,
+// or every worker when indices is nil.
+func runSend(cmd *cobra.Command, args []string, indices []int) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	msg, err := readMessage(cmd, args)
+	if err != nil {
+		return err
+	}
+	cliFilter, _ := cmd.Flags().GetString("cli")
+	busy, _ := cmd.Flags().GetBool("busy")
+
+	if !tmux.HasSession(cfg.Session) {
+		return fmt.Errorf("session %q is not running", cfg.Session)
+	}
+	m, err := state.Load(cfg.Session)
+	if err != nil {
+		return err
+	}
+	targets, err := selectWorkers(m, indices, parseCLITypes(cliFilter))
+	if err != nil {
+		return err
+	}
+
+	sent := 0
+	for _, wk := range targets {
+		if ok, reason := sendable(wk, busy); !ok {
+			fmt.Printf("⏭   worker-%d skipped (%s)\n", wk.Index, reason)
+			continue
+		}
+		if err := tmux.SendText(wk.PaneID, msg); err != nil {
+			fmt.Printf("⚠️   worker-%d: %v\n", wk.Index, err)
+			continue
+		}
+		fmt.Printf("📨  Sent to worker-%d\n", wk.Index)
+		sent++
+	}
+	if sent == 0 {
+		return fmt.Errorf("message not sent to any worker")
+	}
+	return nil
+}
+
+// readMessage returns the message from --file, stdin or the arguments.
+func readMessage(cmd *cobra.Command, args []string) (string, error) {
+	var data []byte
+	var err error
+	file, _ := cmd.Flags().GetString("file")
+	switch {
+	case file != "":
+		if len(args) > 0 {
+			return "", fmt.Errorf("give the message as arguments or with --file, not both")
+		}
+		data, err = os.ReadFile(file)
+	case len(args) == 1 && args[0] == "-", len(args) == 0 && !isTerminal():
+		data, err = io.ReadAll(os.Stdin)
+	default:
+		data = []byte(strings.Join(args, " "))
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading message: %w", err)
+	}
+	msg := strings.TrimSpace(string(data))
+	if msg == "" {
+		return "", fmt.Errorf("empty message")
+	}
+	return msg, nil
+}
+
+// selectWorkers returns the running workers matching indices (all if nil)
+// and, if cliTypes is non-empty, running one of those CLIs.
+func selectWorkers(m *state.Manifest, indices []int, cliTypes []string) ([]state.Worker, error) {
+	var picked []state.Worker
+	if indices == nil {
+		picked = m.Workers
+	} else {
+		for _, i := range indices {
+			wk := m.Worker(i)
+			if wk == nil {
+				return nil, fmt.Errorf("no worker-%d in session %q", i, m.Session)
+			}
+			picked = append(picked, *wk)
+		}
+	}
+	var out []state.Worker
+	for _, wk := range picked {
+		if wk.PaneID == "" {
+			continue
+		}
+		if len(cliTypes) > 0 && !slices.Contains(cliTypes, wk.CLI) {
+			continue
+		}
+		out = append(out, wk)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no running worker matches")
+	}
+	return out, nil
+}
+
+// sendable reports whether a message can be typed into the worker's pane now.
+// busy (--busy) sends to busy workers too, but never into a usage-limit
+// screen, a bare shell or a paused worker.
+func sendable(wk state.Worker, busy bool) (bool, string) {
+	s, err := monitor.Observe(wk.PaneID, wk.CLI)
+	if err != nil {
+		return false, "pane gone"
+	}
+	switch {
+	case s == monitor.Idle:
+		return true, ""
+	case s == monitor.Limited, s == monitor.Exited, s == monitor.Paused:
+		return false, string(s)
+	case busy:
+		return true, ""
+	}
+	return false, string(s) + "; --busy to send anyway"
+}
@@ -6,8 +6,10 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/cpoulin/claude-swarm/internal/git"
+	"github.com/cpoulin/claude-swarm/internal/state"
 	"github.com/spf13/cobra"
 )
 
@@ -21,7 +23,7 @@ Opens an interactive gh pr create, then removes the worktree and branch.`,
 
 func init() {
 	f := shipCmd.Flags()
-	f.StringP("base", "b", "main", "Base branch for the pull request")
+	f.StringP("base", "b", "", "Base branch for the pull request (default: the swarm's base branch, else main)")
 	f.Bool("no-cleanup", false, "Skip worktree cleanup after PR creation")
 	rootCmd.AddCommand(shipCmd)
 }
@@ -29,8 +31,12 @@ func init() {
 func runShip(cmd *cobra.Command, args []string) error {
 	base, _ := cmd.Flags().GetString("base")
 	noCleanup, _ := cmd.Flags().GetBool("no-cleanup")
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
 
-	repoRoot, err := git.RepoRoot()
+	repoRoot, err := git.MainRoot()
 	if err != nil {
 		return fmt.Errorf("not inside a git repository")
 	}
@@ -45,6 +51,20 @@ func runShip(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// The session manifest knows which worker owns this worktree and its base.
+	m, wk, _ := state.FindWorktree(cwd)
+	if base == "" && wk != nil {
+		base = wk.BaseBranch
+	}
+	if base == "" {
+		base = "main"
+	}
+	worktree := cwd
+	if wk != nil {
+		worktree = wk.Worktree
+		repoRoot = m.RepoRoot
+	}
+
 	stdin := bufio.NewReader(os.Stdin)
 
 	// Warn if not in a worktree (branch doesn't look like swarm/*)
@@ -85,29 +105,73 @@ func runShip(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("gh pr create failed: %w", err)
 	}
 
+	if m != nil {
+		url := prURL(branch)
+		_ = updateManifest(m.Session, func(m *state.Manifest) {
+			if w := m.Worker(wk.Index); w != nil {
+				w.Shipped, w.PRURL = true, url
+			}
+		})
+	}
+
 	if noCleanup {
 		fmt.Println("\nℹ️   Skipping cleanup (--no-cleanup).")
 		return nil
 	}
 
-	// Cleanup
-	fmt.Print("\n🧹  Remove worktree and branch? [Y/n] ")
-	answer, _ := stdin.ReadString('\n')
-	answer = strings.TrimSpace(answer)
-	if answer == "" {
-		answer = "Y"
+	// Cleanup: the branch was just pushed, so only uncommitted or unpushed
+	// work makes removal risky.
+	shipped := workerFor(wk, worktree, branch, base)
+	var confirmed bool
+	if c := checkWorker(shipped); c.State >= workUnpushed && !cfg.Force {
+		fmt.Printf("\n⚠️   Worktree is %s (%d unpushed commits, %d dirty files). Remove anyway? [y/N] ", c.State, c.Unpushed, c.Dirty)
+		answer, _ := stdin.ReadString('\n')
+		confirmed = strings.EqualFold(strings.TrimSpace(answer), "y")
+	} else {
+		fmt.Print("\n🧹  Remove worktree and branch? [Y/n] ")
+		answer, _ := stdin.ReadString('\n')
+		answer = strings.TrimSpace(answer)
+		confirmed = answer == "" || strings.EqualFold(answer, "y")
 	}
-	if strings.EqualFold(answer, "y") {
+	if confirmed {
 		if err := os.Chdir(repoRoot); err != nil {
 			fmt.Printf("⚠️   Could not cd to repo root: %v\n", err)
 		}
-		_ = git.RemoveWorktree(cwd)
-		_ = git.DeleteBranch(branch)
+		session := cfg.Session
+		if m != nil {
+			session = m.Session
+		}
+		if err := removeWorker(cfg, session, shipped, time.Now()); err != nil {
+			return err
+		}
 		_ = git.Prune()
+		if m != nil {
+			_ = updateManifest(m.Session, func(m *state.Manifest) {
+				m.RemoveWorker(wk.Index)
+			})
+		}
 		fmt.Println("✅  Cleaned up.")
 	} else {
-		fmt.Printf("ℹ️   Kept. Remove manually: git worktree remove %s\n", cwd)
+		fmt.Printf("ℹ️   Kept. Remove manually: git worktree remove %s\n", worktree)
 	}
 
 	return nil
 }
+
+// prURL returns the URL of the pull request for branch, or "" if gh cannot find one.
+func prURL(branch string) string {
+	out, err := exec.Command("gh", "pr", "view", branch, "--json", "url", "--jq", ".url").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// workerFor returns the manifest entry of the shipped worktree, or a stand-in
+// built from the current checkout when it is not a recorded swarm worker.
+func workerFor(wk *state.Worker, worktree, branch, base string) state.Worker {
+	if wk != nil {
+		return *wk
+	}
+	return state.Worker{Worktree: worktree, Branch: branch, BaseBranch: base}
+}
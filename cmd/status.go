@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cpoulin/claude-swarm/internal/git"
+	"github.com/cpoulin/claude-swarm/internal/monitor"
+	"github.com/cpoulin/claude-swarm/internal/state"
+	"github.com/cpoulin/claude-swarm/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show one row per worker: branch, progress, activity and state",
+	Long: `Shows every worker of the session: CLI and model, branch, commits ahead of
+the base branch, diffstat, uncommitted files, last screen activity, monitor
+state (with the resume time of a usage-limited worker) and the PR URL of
+shipped workers. --json prints the same as a JSON document.`,
+	RunE: runStatus,
+}
+
+func init() {
+	statusCmd.Flags().Bool("json", false, "Print JSON instead of a table")
+	rootCmd.AddCommand(statusCmd)
+}
+
+// stateStopped is reported for workers without a live pane.
+const stateStopped = "stopped"
+
+// workerStatus is one row of the status command.
+type workerStatus struct {
+	Worker       int       `json:"worker"`
+	CLI          string    `json:"cli"`
+	Model        string    `json:"model,omitempty"`
+	Label        string    `json:"label,omitempty"`
+	PaneID       string    `json:"pane_id,omitempty"`
+	Worktree     string    `json:"worktree"`
+	Branch       string    `json:"branch"`
+	BaseBranch   string    `json:"base_branch"`
+	Ahead        int       `json:"ahead"`
+	FilesChanged int       `json:"files_changed"`
+	Insertions   int       `json:"insertions"`
+	Deletions    int       `json:"deletions"`
+	Dirty        int       `json:"dirty"`
+	State        string    `json:"state"`
+	StateSince   time.Time `json:"state_since,omitzero"`
+	LastActivity time.Time `json:"last_activity,omitzero"`
+	ResumeAt     time.Time `json:"resume_at,omitzero"`
+	Shipped      bool      `json:"shipped"`
+	PRURL        string    `json:"pr_url,omitempty"`
+}
+
+// sessionStatus is the output of the status command.
+type sessionStatus struct {
+	Session string         `json:"session"`
+	Running bool           `json:"running"`
+	Workers []workerStatus `json:"workers"`
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	m, err := state.Load(cfg.Session)
+	if err != nil {
+		return fmt.Errorf("no swarm recorded under %q: %w", cfg.Session, err)
+	}
+
+	st := sessionStatus{Session: m.Session, Running: tmux.HasSession(m.Session)}
+	for _, wk := range m.Workers {
+		st.Workers = append(st.Workers, statusOf(wk, st.Running))
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(st)
+	}
+	printStatus(st)
+	return nil
+}
+
+// statusOf gathers the git and monitor state of one worker.
+func statusOf(wk state.Worker, running bool) workerStatus {
+	// AHEAD and DIFF both describe the current branch; cleanup's check also
+	// counts the branches of earlier queued tasks.
+	current := wk
+	current.PastBranches = nil
+	c := checkWorker(current)
+	ws := workerStatus{
+		Worker:     wk.Index,
+		CLI:        wk.CLI,
+		Model:      wk.Model,
+		Label:      wk.Label,
+		PaneID:     wk.PaneID,
+		Worktree:   wk.Worktree,
+		Branch:     wk.Branch,
+		BaseBranch: wk.BaseBranch,
+		Ahead:      c.Ahead,
+		Dirty:      c.Dirty,
+		State:      stateStopped,
+		Shipped:    wk.Shipped,
+		PRURL:      wk.PRURL,
+	}
+	base := wk.BaseBranch
+	if _, err := git.RevParse(base); err != nil {
+		base = wk.BaseCommit
+	}
+	ws.FilesChanged, ws.Insertions, ws.Deletions, _ = git.DiffStat(base, wk.Branch)
+
+	if !running || wk.PaneID == "" {
+		return ws
+	}
+	if ps, ok := monitor.ReadPaneStatus(wk.PaneID); ok {
+		ws.State = string(ps.State)
+		ws.StateSince, ws.LastActivity, ws.ResumeAt = ps.Since, ps.Activity, ps.ResumeAt
+	} else if s, err := monitor.Observe(wk.PaneID, wk.CLI); err == nil {
+		ws.State = string(s) // no monitor running: read the pane now
+	}
+	return ws
+}
+
+func printStatus(st sessionStatus) {
+	running := "running"
+	if !st.Running {
+		running = "not running"
+	}
+	fmt.Printf("📺  Session %q (%s)\n\n", st.Session, running)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  WORKER\tCLI\tBRANCH\tAHEAD\tDIFF\tDIRTY\tACTIVE\tSTATE\tPR")
+	for _, ws := range st.Workers {
+		cli := ws.CLI
+		if ws.Model != "" {
+			cli += ":" + ws.Model
+		}
+		diff := "-"
+		if ws.FilesChanged > 0 {
+			files := "files"
+			if ws.FilesChanged == 1 {
+				files = "file"
+			}
+			diff = fmt.Sprintf("%d %s +%d -%d", ws.FilesChanged, files, ws.Insertions, ws.Deletions)
+		}
+		stateCol := ws.State
+		if !ws.ResumeAt.IsZero() {
+			stateCol += " (until " + ws.ResumeAt.Local().Format("15:04") + ")"
+		}
+		pr := ws.PRURL
+		if pr == "" {
+			pr = "-"
+			if ws.Shipped {
+				pr = "shipped"
+			}
+		}
+		fmt.Fprintf(tw, "  %d\t%s\t%s\t%d\t%s\t%d\t%s\t%s\t%s\n",
+			ws.Worker, cli, ws.Branch, ws.Ahead, diff, ws.Dirty, ago(ws.LastActivity), stateCol, pr)
+	}
+	_ = tw.Flush()
+}
+
+// ago formats the time since t for the status table.
+func ago(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
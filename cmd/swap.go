@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/cpoulin/claude-swarm/internal/config"
+	"github.com/cpoulin/claude-swarm/internal/monitor"
+	"github.com/cpoulin/claude-swarm/internal/proc"
+	"github.com/cpoulin/claude-swarm/internal/state"
+	"github.com/cpoulin/claude-swarm/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var swapCmd = &cobra.Command{
+	Use:   "swap -w N -t cli[:model]",
+	Short: "Restart one worker with a different CLI or model on the same worktree",
+	Long: `Kills whatever runs in the worker's pane and starts the new CLI there, in the
+same worktree and on the same branch. The pane keeps its place; its title,
+the session manifest and the worker's monitor follow the new CLI.`,
+	RunE: runSwap,
+}
+
+func init() {
+	f := swapCmd.Flags()
+	f.IntP("worker", "w", 0, "Worker number to swap")
+	f.StringP("type", "t", "", "New CLI, optionally with a model: claude|gemini|codex[:model]")
+	f.String("cli-flags", "", "Flags for the new CLI (the old worker's flags are dropped)")
+	f.String("prompt", "", "Prompt to start the new CLI with")
+	_ = swapCmd.MarkFlagRequired("worker")
+	_ = swapCmd.MarkFlagRequired("type")
+	rootCmd.AddCommand(swapCmd)
+}
+
+func runSwap(cmd *cobra.Command, args []string) error {
+	idx, _ := cmd.Flags().GetInt("worker")
+	cliType, _ := cmd.Flags().GetString("type")
+	flags, _ := cmd.Flags().GetString("cli-flags")
+	prompt, _ := cmd.Flags().GetString("prompt")
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	cliName, model := parseWorker(cliType)
+	if !isSupportedCLIType(cliName) {
+		return fmt.Errorf("unsupported CLI %q — use claude, gemini, or codex", cliName)
+	}
+	if !commandExists(cliName) {
+		return fmt.Errorf("%s not found in PATH", cliName)
+	}
+
+	if !tmux.HasSession(cfg.Session) {
+		return fmt.Errorf("session %q is not running", cfg.Session)
+	}
+	wk, err := swapWorker(cfg, idx, state.Worker{CLI: cliName, Model: model, Flags: flags, Prompt: prompt})
+	if err != nil {
+		return err
+	}
+	deliverPrompts(cfg, []state.Worker{wk}, os.Stdout)
+	return nil
+}
+
+// swapWorker restarts worker idx with the CLI, model, flags and prompt of to
+// under the session lock and returns the updated worker.
+func swapWorker(cfg *config.Config, idx int, to state.Worker) (state.Worker, error) {
+	unlock, err := lockSession(cfg.Session)
+	if err != nil {
+		return state.Worker{}, err
+	}
+	defer unlock()
+
+	m, err := state.Load(cfg.Session)
+	if err != nil {
+		return state.Worker{}, err
+	}
+	wk := m.Worker(idx)
+	if wk == nil {
+		return state.Worker{}, fmt.Errorf("no worker-%d in session %q", idx, cfg.Session)
+	}
+	if wk.PaneID == "" {
+		return state.Worker{}, fmt.Errorf("worker-%d has no pane — it was removed or the swarm is down", idx)
+	}
+
+	// A paused worker's processes are stopped and would not see the hangup
+	// of the respawn; they are continued first, and the new CLI runs unpaused.
+	paused := monitor.IsPaused(wk.PaneID)
+	if paused {
+		if pid, err := tmux.DisplayFormat(wk.PaneID, "#{pane_pid}"); err == nil {
+			if n, err := strconv.Atoi(pid); err == nil {
+				_, _ = proc.Cont(n)
+			}
+		}
+	}
+	old := wk.Spec()
+	wk.CLI, wk.Model, wk.Flags, wk.Prompt = to.CLI, to.Model, to.Flags, to.Prompt
+	if err := tmux.RespawnPane(wk.PaneID, wk.StartDir()); err != nil {
+		return state.Worker{}, err
+	}
+	if paused {
+		if err := monitor.MarkPaused(wk.PaneID, false); err != nil {
+			return state.Worker{}, err
+		}
+	}
+	launchWorker(cfg, *wk)
+	if window, err := tmux.DisplayFormat(wk.PaneID, "#{window_id}"); err == nil {
+		_ = tmux.SelectLayout(window, cfg.Layout)
+	}
+	if err := state.Save(m); err != nil {
+		return state.Worker{}, fmt.Errorf("saving session manifest: %w", err)
+	}
+	fmt.Printf("🔁  worker-%d: %s → %s (branch %s)\n", idx, old, wk.Spec(), wk.Branch)
+	return *wk, nil
+}
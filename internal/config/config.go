@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 )
 
@@ -11,22 +13,78 @@ type Config struct {
 	CLIType         string `mapstructure:"cli_type"`
 	CLIFlags        string `mapstructure:"cli_flags"`
 	AddMode         bool   `mapstructure:"add_mode"`
+	Spec            string `mapstructure:"spec"`
+	Tasks           string `mapstructure:"tasks"`
+	PromptDelivery  string `mapstructure:"prompt_delivery"`
+	PromptReadySecs int    `mapstructure:"prompt_ready_timeout"`
+	QueueDoneOn     string `mapstructure:"queue_done_on"`
+	QueueIdleSecs   int    `mapstructure:"queue_idle_secs"`
+	QueueClear      bool   `mapstructure:"queue_clear"`
 	ResumeBufferSec int    `mapstructure:"resume_buffer_secs"`
+	LimitLines      int    `mapstructure:"limit_status_lines"`
+	LimitClearHist  bool   `mapstructure:"limit_clear_history"`
+	ContinuePrompt  string `mapstructure:"continue_prompt"`
 	MonitorInterval int    `mapstructure:"monitor_interval"`
+	MaxRestarts     int    `mapstructure:"max_restarts"`
+	RestartBackoff  int    `mapstructure:"restart_backoff_secs"`
+	StallTimeout    int    `mapstructure:"stall_timeout_secs"`
+	StallAction     string `mapstructure:"stall_action"`
+	StallNudge      string `mapstructure:"stall_nudge"`
 	WorktreePrefix  string `mapstructure:"worktree_prefix"`
+	WorktreeRoot    string `mapstructure:"worktree_root"`
+	Layout          string `mapstructure:"layout"`
+	MaxPanes        int    `mapstructure:"max_panes_per_window"`
+	Daemon          bool   `mapstructure:"daemon"`
+	Force           bool   `mapstructure:"force"`
+	Archive         bool   `mapstructure:"archive_on_cleanup"`
+	DownPolicy      string `mapstructure:"down_policy"`
+
+	// LimitRules are extra usage-limit messages per provider (claude,
+	// gemini, codex, or any).
+	LimitRules map[string][]LimitRule `mapstructure:"limit_rules"`
+}
+
+// LimitRule is a user-defined usage-limit message; see usagelimit.Rule.
+type LimitRule struct {
+	Name        string        `mapstructure:"name"`
+	Match       string        `mapstructure:"match"`
+	Reset       string        `mapstructure:"reset"`
+	Kind        string        `mapstructure:"kind"`
+	DefaultWait time.Duration `mapstructure:"default_wait"`
 }
 
 // SetDefaults registers viper defaults.
 func SetDefaults() {
 	viper.SetDefault("num", 4)
-	viper.SetDefault("session", "claude-swarm")
+	viper.SetDefault("session", "")
 	viper.SetDefault("base_branch", "")
 	viper.SetDefault("cli_type", "claude,claude,gemini:gemini-3-flash,gemini:gemini-3.1-pro")
 	viper.SetDefault("cli_flags", "")
 	viper.SetDefault("add_mode", false)
+	viper.SetDefault("spec", "")
+	viper.SetDefault("tasks", "")
+	viper.SetDefault("prompt_delivery", "arg")
+	viper.SetDefault("prompt_ready_timeout", 90)
+	viper.SetDefault("queue_done_on", "sentinel,commit,idle")
+	viper.SetDefault("queue_idle_secs", 120)
+	viper.SetDefault("queue_clear", true)
 	viper.SetDefault("resume_buffer_secs", 120)
+	viper.SetDefault("limit_status_lines", 12)
+	viper.SetDefault("limit_clear_history", false)
+	viper.SetDefault("continue_prompt", "The usage limit has reset. Continue where you left off.")
 	viper.SetDefault("monitor_interval", 30)
+	viper.SetDefault("max_restarts", 5)
+	viper.SetDefault("restart_backoff_secs", 10)
+	viper.SetDefault("stall_timeout_secs", 1800)
+	viper.SetDefault("stall_action", "none")
+	viper.SetDefault("stall_nudge", "You have not made visible progress for a while. If you are stuck, say what is blocking you; otherwise carry on with the task.")
 	viper.SetDefault("worktree_prefix", ".wt")
+	viper.SetDefault("worktree_root", "")
+	viper.SetDefault("layout", "tiled")
+	viper.SetDefault("max_panes_per_window", 6)
+	viper.SetDefault("daemon", false)
+	viper.SetDefault("archive_on_cleanup", true)
+	viper.SetDefault("down_policy", "remove")
 }
 
 // Load unmarshals viper settings into a Config.
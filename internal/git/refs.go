@@ -0,0 +1,118 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// SnapshotSubject prefixes the message of commits created by SnapshotWorktree.
+const SnapshotSubject = "claude-swarm snapshot:"
+
+// Ref is one entry of ListRefs.
+type Ref struct {
+	Name    string
+	SHA     string
+	Subject string
+}
+
+// SnapshotWorktree records the uncommitted changes of the worktree at dir —
+// including untracked files — as a commit on top of HEAD, without touching
+// the worktree or its index. It returns HEAD itself when the worktree is clean.
+func SnapshotWorktree(dir, label string) (sha string, err error) {
+	head, err := gitOutput(nil, "-C", dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	dirty, err := DirtyFiles(dir)
+	if err != nil || dirty == 0 {
+		return head, err
+	}
+
+	idx, err := os.CreateTemp("", "claude-swarm-index-")
+	if err != nil {
+		return "", err
+	}
+	idx.Close()
+	defer os.Remove(idx.Name())
+	env := append(os.Environ(), "GIT_INDEX_FILE="+idx.Name())
+
+	if _, err := gitOutput(env, "-C", dir, "read-tree", "HEAD"); err != nil {
+		return "", err
+	}
+	if _, err := gitOutput(env, "-C", dir, "add", "-A"); err != nil {
+		return "", err
+	}
+	tree, err := gitOutput(env, "-C", dir, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	return gitOutput(nil, "-C", dir, "commit-tree", tree, "-p", head,
+		"-m", SnapshotSubject+" uncommitted changes of "+label)
+}
+
+// UpdateRef points ref at sha, creating it if needed.
+func UpdateRef(ref, sha, reason string) error {
+	_, err := gitOutput(nil, "update-ref", "-m", reason, ref, sha)
+	return err
+}
+
+// DeleteRef deletes ref.
+func DeleteRef(ref string) error {
+	_, err := gitOutput(nil, "update-ref", "-d", ref)
+	return err
+}
+
+// ListRefs returns every ref under prefix (e.g. "refs/swarm-archive/"), sorted by name.
+func ListRefs(prefix string) ([]Ref, error) {
+	out, err := gitOutput(nil, "for-each-ref", "--sort=refname",
+		"--format=%(refname)%09%(objectname)%09%(contents:subject)", prefix)
+	if err != nil {
+		return nil, err
+	}
+	var refs []Ref
+	for _, line := range strings.Split(out, "\n") {
+		f := strings.SplitN(line, "\t", 3)
+		if len(f) < 3 {
+			continue
+		}
+		refs = append(refs, Ref{Name: f[0], SHA: f[1], Subject: f[2]})
+	}
+	return refs, nil
+}
+
+// CreateBranch creates branch at rev without checking it out.
+func CreateBranch(branch, rev string) error {
+	_, err := gitOutput(nil, "branch", branch, rev)
+	return err
+}
+
+// ApplyDiff applies the changes between from and to to the worktree at dir, unstaged.
+func ApplyDiff(dir, from, to string) error {
+	diff := exec.Command("git", "diff", "--binary", from, to)
+	patch, err := diff.Output()
+	if err != nil {
+		return fmt.Errorf("git diff %s %s: %w", from, to, err)
+	}
+	apply := exec.Command("git", "-C", dir, "apply", "--whitespace=nowarn", "-")
+	apply.Stdin = strings.NewReader(string(patch))
+	if out, err := apply.CombinedOutput(); err != nil {
+		return fmt.Errorf("git apply: %w\n%s", err, out)
+	}
+	return nil
+}
+
+func gitOutput(env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Env = env
+	out, err := cmd.Output()
+	if err != nil {
+		msg := ""
+		if ee, ok := err.(*exec.ExitError); ok {
+			msg = "\n" + strings.TrimSpace(string(ee.Stderr))
+		}
+		return "", fmt.Errorf("git %s: %w%s", strings.Join(args, " "), err, msg)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
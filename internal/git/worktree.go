@@ -2,7 +2,9 @@ package git
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
@@ -71,3 +73,160 @@ func BranchOfWorktree(dir string) (string, error) {
 	}
 	return strings.TrimSpace(string(out)), nil
 }
+
+// CommonDir returns the absolute path of the shared .git directory, which is
+// the same for the main checkout and every linked worktree.
+func CommonDir() (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--path-format=absolute", "--git-common-dir").Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse --git-common-dir: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// RevParse resolves a revision (branch, tag, …) to its full commit SHA.
+func RevParse(rev string) (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--verify", "--quiet", rev+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse %s: %w", rev, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// CountCommits returns the number of commits reachable from rev but not from
+// any of the excluded revisions.
+func CountCommits(rev string, exclude ...string) (int, error) {
+	args := append([]string{"rev-list", "--count", rev, "--not"}, exclude...)
+	return revListCount(args)
+}
+
+// UnpushedCommits returns the number of commits on rev that are neither on
+// base nor on any remote-tracking branch.
+func UnpushedCommits(rev, base string) (int, error) {
+	return revListCount([]string{"rev-list", "--count", rev, "--not", base, "--remotes"})
+}
+
+func revListCount(args []string) (int, error) {
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return 0, fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	var n int
+	if _, err := fmt.Sscanf(strings.TrimSpace(string(out)), "%d", &n); err != nil {
+		return 0, fmt.Errorf("parsing rev-list count: %w", err)
+	}
+	return n, nil
+}
+
+// DirtyFiles returns the number of modified, staged or untracked paths in the worktree at dir.
+func DirtyFiles(dir string) (int, error) {
+	out, err := exec.Command("git", "-C", dir, "status", "--porcelain").Output()
+	if err != nil {
+		return 0, fmt.Errorf("git -C %s status: %w", dir, err)
+	}
+	n := 0
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.TrimSpace(line) != "" {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// CheckoutWorktree creates a new worktree at dir with an existing branch checked out.
+func CheckoutWorktree(dir, branch string) error {
+	cmd := exec.Command("git", "worktree", "add", dir, branch, "-q")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git worktree add: %w\n%s", err, out)
+	}
+	return nil
+}
+
+// MainRoot returns the root of the main checkout, even when called from inside
+// a linked worktree (where RepoRoot returns the worktree itself).
+func MainRoot() (string, error) {
+	common, err := CommonDir()
+	if err != nil {
+		return "", err
+	}
+	if filepath.Base(common) == ".git" {
+		return filepath.Dir(common), nil
+	}
+	return RepoRoot()
+}
+
+// AddExclude appends pattern to the repository's info/exclude file unless it is already listed.
+func AddExclude(pattern string) error {
+	common, err := CommonDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(common, "info", "exclude")
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		pattern = "\n" + pattern
+	}
+	_, err = f.WriteString(pattern + "\n")
+	return err
+}
+
+// SwitchBranch checks out branch in the worktree at dir, creating it at start
+// if it does not exist yet.
+func SwitchBranch(dir, branch, start string) error {
+	args := []string{"-C", dir, "checkout", "-q", branch}
+	if _, err := RevParse("refs/heads/" + branch); err != nil {
+		args = []string{"-C", dir, "checkout", "-q", "-b", branch, start}
+	}
+	_, err := gitOutput(nil, args...)
+	return err
+}
+
+// CommitAll stages every change in the worktree at dir and commits it.
+func CommitAll(dir, message string) error {
+	if _, err := gitOutput(nil, "-C", dir, "add", "-A"); err != nil {
+		return err
+	}
+	_, err := gitOutput(nil, "-C", dir, "commit", "-q", "--no-verify", "-m", message)
+	return err
+}
+
+// DiffStat returns the files changed, insertions and deletions of rev since
+// it forked from base.
+func DiffStat(base, rev string) (files, insertions, deletions int, err error) {
+	out, err := gitOutput(nil, "diff", "--shortstat", base+"..."+rev)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	for _, part := range strings.Split(out, ",") {
+		var n int
+		var what string
+		if _, err := fmt.Sscanf(strings.TrimSpace(part), "%d %s", &n, &what); err != nil {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(what, "file"):
+			files = n
+		case strings.HasPrefix(what, "insertion"):
+			insertions = n
+		case strings.HasPrefix(what, "deletion"):
+			deletions = n
+		}
+	}
+	return files, insertions, deletions, nil
+}
@@ -0,0 +1,73 @@
+// Package layout decides how swarm workers are spread across tmux windows.
+package layout
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Supported tmux layouts for swarm windows.
+const (
+	Tiled          = "tiled"
+	MainVertical   = "main-vertical"
+	EvenHorizontal = "even-horizontal"
+)
+
+// WindowPrefix is the name of the first swarm window; overflow windows are
+// named "swarm-2", "swarm-3", …
+const WindowPrefix = "swarm"
+
+// Valid reports whether name is a supported layout.
+func Valid(name string) bool {
+	switch name {
+	case Tiled, MainVertical, EvenHorizontal:
+		return true
+	default:
+		return false
+	}
+}
+
+// WindowName returns the name of the n-th (0-based) swarm window.
+func WindowName(n int) string {
+	if n == 0 {
+		return WindowPrefix
+	}
+	return fmt.Sprintf("%s-%d", WindowPrefix, n+1)
+}
+
+// WindowNumber parses a swarm window name back into its 0-based position.
+// ok is false for windows that are not swarm windows (e.g. "hub").
+func WindowNumber(name string) (n int, ok bool) {
+	if name == WindowPrefix {
+		return 0, true
+	}
+	rest, found := strings.CutPrefix(name, WindowPrefix+"-")
+	if !found {
+		return 0, false
+	}
+	v, err := strconv.Atoi(rest)
+	if err != nil || v < 2 {
+		return 0, false
+	}
+	return v - 1, true
+}
+
+// Plan splits n workers into consecutive windows of at most maxPerWindow panes
+// and returns the pane count of each window. A non-positive maxPerWindow puts
+// every worker in a single window.
+func Plan(n, maxPerWindow int) []int {
+	if n <= 0 {
+		return nil
+	}
+	if maxPerWindow <= 0 || maxPerWindow >= n {
+		return []int{n}
+	}
+	sizes := make([]int, 0, (n+maxPerWindow-1)/maxPerWindow)
+	for n > 0 {
+		size := min(n, maxPerWindow)
+		sizes = append(sizes, size)
+		n -= size
+	}
+	return sizes
+}
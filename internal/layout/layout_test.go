@@ -0,0 +1,41 @@
+package layout
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlan(t *testing.T) {
+	cases := []struct {
+		n, max int
+		want   []int
+	}{
+		{0, 6, nil},
+		{1, 6, []int{1}},
+		{2, 6, []int{2}},
+		{6, 6, []int{6}},
+		{7, 6, []int{6, 1}},
+		{13, 6, []int{6, 6, 1}},
+		{5, 0, []int{5}},
+	}
+	for _, tc := range cases {
+		if got := Plan(tc.n, tc.max); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Plan(%d, %d) = %v, want %v", tc.n, tc.max, got, tc.want)
+		}
+	}
+}
+
+func TestWindowName(t *testing.T) {
+	for n := 0; n < 4; n++ {
+		name := WindowName(n)
+		got, ok := WindowNumber(name)
+		if !ok || got != n {
+			t.Errorf("WindowNumber(%q) = %d, %v, want %d, true", name, got, ok, n)
+		}
+	}
+	for _, name := range []string{"hub", "swarm-1", "swarm-x", "swarmy"} {
+		if _, ok := WindowNumber(name); ok {
+			t.Errorf("WindowNumber(%q) unexpectedly ok", name)
+		}
+	}
+}
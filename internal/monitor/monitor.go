@@ -11,17 +11,104 @@ import (
 	"github.com/cpoulin/claude-swarm/internal/usagelimit"
 )
 
-// Watch polls a pane for API usage-limit errors and automatically resumes.
-// paneID is the stable %N tmux pane identifier.
-func Watch(ctx context.Context, cfg *config.Config, session, paneID string, workerNum int, cliCmd string, w io.Writer) {
+// Target identifies one worker pane to watch.
+// PaneID is the stable %N tmux pane identifier; Title is the pane title the
+// worker was launched with, which the monitor decorates with its state.
+// ResumeCmd relaunches the CLI on its last conversation after it exits.
+type Target struct {
+	PaneID    string
+	Worker    int
+	CLI       string
+	ResumeCmd string
+	Title     string
+}
+
+// Crash restarts: the delay doubles from restart_backoff_secs up to
+// maxRestartDelay. A CLI that stays up for restartReset gets its full
+// max_restarts budget back, and a pane still at its shell restartGrace after
+// the monitor started or relaunched the CLI counts as exited.
+const (
+	maxRestartDelay = 5 * time.Minute
+	restartReset    = 10 * time.Minute
+	restartGrace    = time.Minute
+)
+
+// restartDelay is the wait before restart number n (counting from 0).
+func restartDelay(base time.Duration, n int) time.Duration {
+	d := base
+	for i := 0; i < n && d < maxRestartDelay; i++ {
+		d *= 2
+	}
+	return min(d, maxRestartDelay)
+}
+
+// launch tells a shell that is still launching a worker's CLI from one the
+// CLI exited to.
+type launch struct {
+	started bool      // the CLI has been seen running
+	since   time.Time // when the monitor started, or last relaunched the CLI
+}
+
+// resolve maps a classified state to the worker's state. An unrecognised
+// screen after startup is the CLI at work, and a shell before it is the CLI
+// still launching — unless that has taken restartGrace, when the CLI died
+// early or was never running when the monitor started.
+func (l *launch) resolve(s State, now time.Time) State {
+	if s == Exited && !l.started && now.Sub(l.since) >= restartGrace {
+		l.started = true
+	}
+	switch {
+	case s == Starting && l.started:
+		s = Working
+	case s == Exited && !l.started:
+		s = Starting
+	}
+	l.started = l.started || s != Starting
+	return s
+}
+
+// Watch polls a pane, classifies the worker's state on every poll (usage
+// limits with the rules of limits),
+// automatically resumes after API usage limits (with continue_prompt if the
+// CLI is still running, or by relaunching it) and relaunches a CLI that
+// exited, with backoff, up to max_restarts times. A worker whose screen shows
+// no progress for stall_timeout_secs while it works is Stalled and, depending
+// on stall_action, nudged. A usage-limit message is only acted on once: the
+// monitor records its place in the scrollback on resume (after clearing the
+// history, with limit_clear_history) and only reacts to messages below it.
+// State changes are logged, shown in the pane title and recorded in the
+// pane's @swarm-state option. A paused pane is not read at all until it is
+// resumed.
+func Watch(ctx context.Context, cfg *config.Config, limits *usagelimit.Parser, session string, t Target, w io.Writer) {
+	paneID, workerNum := t.PaneID, t.Worker
 	interval := time.Duration(cfg.MonitorInterval) * time.Second
-	detected := false
+	title := t.Title
+	if title == "" {
+		title = fmt.Sprintf("worker-%d", workerNum)
+	}
 
 	logf := func(format string, args ...any) {
 		msg := fmt.Sprintf(time.Now().UTC().Format("2006-01-02T15:04:05Z")+" "+format+"\n", args...)
 		fmt.Fprint(w, msg)
 	}
 
+	var cur State
+	stall := stallWatch{after: time.Duration(cfg.StallTimeout) * time.Second, changed: time.Now()}
+	cli := launch{since: time.Now()}
+	restarts := 0
+	var restartedAt time.Time
+	setState := func(s State) {
+		if s == cur {
+			return
+		}
+		if cur != "" {
+			logf("[worker-%d] %s → %s.", workerNum, cur, s)
+		}
+		cur = s
+		recordState(paneID, s, time.Now())
+		_ = tmux.SetPaneTitle(paneID, fmt.Sprintf("%s [%s]", title, s))
+	}
+
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 	for {
@@ -31,26 +118,85 @@ func Watch(ctx context.Context, cfg *config.Config, session, paneID string, work
 		case <-ticker.C:
 		}
 
-		content, err := tmux.CapturePane(paneID)
+		command, paused, err := foreground(paneID)
 		if err != nil {
 			return // pane gone
 		}
+		if paused {
+			_, _ = stall.resolve(Paused, time.Now())
+			setState(Paused)
+			continue
+		}
+		content, history, err := tmux.CapturePaneStyled(paneID)
+		if err != nil {
+			return
+		}
+		now := time.Now()
+		if stall.see(progressKey(t.CLI, usagelimit.Strip(content)), now) {
+			recordTime(paneID, ActivityOption, now)
+		}
+		s, limit := ClassifyPane(limits, paneID, t.CLI, content, history, command)
+		s, stalled := stall.resolve(cli.resolve(s, now), now)
+		prev := cur
+		setState(s)
+
+		if stalled {
+			logf("[worker-%d] No progress for %s.", workerNum, now.Sub(stall.changed).Round(time.Second))
+			if cfg.StallAction == StallNudge || cfg.StallAction == StallInterrupt {
+				logf("[worker-%d] Nudging (%s).", workerNum, cfg.StallAction)
+				_ = nudge(ctx, paneID, cfg.StallAction, cfg.StallNudge)
+			}
+		}
 
-		if !detected && usagelimit.HasError(content) {
-			detected = true
+		if cur == Exited && t.ResumeCmd != "" {
+			if !restartedAt.IsZero() && time.Since(restartedAt) >= restartReset {
+				restarts = 0
+			}
+			if restarts >= cfg.MaxRestarts {
+				if prev != Exited {
+					logf("[worker-%d] CLI exited; all %d restart(s) used, leaving it at the shell.", workerNum, cfg.MaxRestarts)
+				}
+				continue
+			}
+			delay := restartDelay(time.Duration(cfg.RestartBackoff)*time.Second, restarts)
+			restarts++
+			logf("[worker-%d] CLI exited. Restart %d/%d in %s.", workerNum, restarts, cfg.MaxRestarts, delay)
+			_ = tmux.SetPaneTitle(paneID, fmt.Sprintf("%s [restart in %s]", title, delay))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			// Someone may have relaunched or paused the worker meanwhile.
+			command, paused, err := foreground(paneID)
+			if err != nil {
+				return
+			}
+			if paused || !IsShell(command) {
+				logf("[worker-%d] Restart skipped: the pane is no longer at its shell.", workerNum)
+				continue
+			}
+			logf("[worker-%d] Relaunching: %s", workerNum, t.ResumeCmd)
+			_ = tmux.SendKeys(paneID, t.ResumeCmd)
+			restartedAt = time.Now()
+			cli = launch{since: restartedAt}
+			setState(Starting)
+			continue
+		}
 
-			waitSecs := usagelimit.ExtractWaitSecs(content)
-			totalSecs := waitSecs + cfg.ResumeBufferSec
+		if cur == Limited {
+			totalSecs := int(limit.Wait.Seconds()) + cfg.ResumeBufferSec
 			displayH := totalSecs / 3600
 			displayM := (totalSecs % 3600) / 60
 
-			logf("[worker-%d] API usage limit hit. Resuming in %dh %dm.", workerNum, displayH, displayM)
-
-			title := fmt.Sprintf("worker-%d [wait %dh%dm]", workerNum, displayH, displayM)
-			_ = tmux.SetPaneTitle(paneID, title)
+			logf("[worker-%d] API usage limit hit: %q (%s limit, resets %s, %s). Resuming in %dh %dm.",
+				workerNum, limit.Matched, limit.Kind, limit.ResetAt.Local().Format("Mon 15:04 MST"), limit.Confidence, displayH, displayM)
+			_ = tmux.SetPaneTitle(paneID, fmt.Sprintf("%s [wait %dh%dm]", title, displayH, displayM))
 
 			deadline := time.Now().Add(time.Duration(totalSecs) * time.Second)
-			for time.Now().Before(deadline) {
+			recordTime(paneID, ResumeAtOption, deadline)
+			// A worker paused during the wait stays paused past the deadline.
+			for time.Now().Before(deadline) || IsPaused(paneID) {
 				select {
 				case <-ctx.Done():
 					return
@@ -62,10 +208,27 @@ func Watch(ctx context.Context, cfg *config.Config, session, paneID string, work
 				return
 			}
 
-			logf("[worker-%d] Resuming with %s --continue.", workerNum, cliCmd)
-			_ = tmux.SendKeys(paneID, cliCmd+" --continue")
-			_ = tmux.SetPaneTitle(paneID, fmt.Sprintf("worker-%d", workerNum))
-			detected = false
+			markHandled(limits, paneID, t.CLI, cfg.LimitClearHist)
+			command, _, err := foreground(paneID)
+			if err != nil {
+				return
+			}
+			if !IsShell(command) {
+				// The CLI stayed up at its prompt: a command line would be a chat message.
+				logf("[worker-%d] Resuming: the CLI is still running, sending continue_prompt.", workerNum)
+				_ = tmux.SendText(paneID, cfg.ContinuePrompt)
+				setState(Working)
+				continue
+			}
+			if t.ResumeCmd == "" {
+				logf("[worker-%d] CLI exited during the limit and has no resume command; leaving it at the shell.", workerNum)
+				continue
+			}
+			logf("[worker-%d] Resuming: %s", workerNum, t.ResumeCmd)
+			_ = tmux.SendKeys(paneID, t.ResumeCmd)
+			restartedAt = time.Now()
+			cli = launch{since: restartedAt}
+			setState(Starting)
 		}
 	}
 }
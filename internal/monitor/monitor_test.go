@@ -0,0 +1,185 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cpoulin/claude-swarm/internal/usagelimit"
+)
+
+func TestRestartDelay(t *testing.T) {
+	tests := []struct {
+		n    int
+		want time.Duration
+	}{
+		{0, 10 * time.Second},
+		{1, 20 * time.Second},
+		{3, 80 * time.Second},
+		{5, maxRestartDelay},
+		{60, maxRestartDelay},
+	}
+	for _, tt := range tests {
+		if got := restartDelay(10*time.Second, tt.n); got != tt.want {
+			t.Errorf("restartDelay(10s, %d) = %s, want %s", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestProgressKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		cli    string
+		a, b   string
+		moving bool
+	}{
+		{"claude spinner ticks", "claude",
+			"● Edit main.go\n✻ Thinking… (12s · ↑ 1.2k tokens · esc to interrupt)\n",
+			"● Edit main.go\n✶ Thinking… (74s · ↑ 1.9k tokens · esc to interrupt)\n", false},
+		{"codex timer ticks", "codex",
+			"• Working (5s • Esc to interrupt)\n", "◦ Working (65s • Esc to interrupt)\n", false},
+		{"new output", "claude",
+			"● Edit main.go\n✻ Thinking… (12s · esc to interrupt)\n",
+			"● Edit main.go\n● Bash(go test ./...)\n✻ Thinking… (13s · esc to interrupt)\n", true},
+		{"numbers outside the busy line", "claude",
+			"ok  pkg 0.4s\n✻ Running… (3s · esc to interrupt)\n",
+			"ok  pkg 0.5s\n✻ Running… (4s · esc to interrupt)\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if moved := progressKey(tt.cli, tt.a) != progressKey(tt.cli, tt.b); moved != tt.moving {
+				t.Errorf("progress between snapshots = %v, want %v", moved, tt.moving)
+			}
+		})
+	}
+}
+
+func TestHandledLimitCovers(t *testing.T) {
+	const msg = "You've hit your usage limit. Try again in 4h."
+	screen := func(above int) string {
+		return strings.Repeat("output\n", above) + msg + "\n? for shortcuts\n"
+	}
+	parse := func(s string) usagelimit.Result {
+		return usagelimit.Default().Parse("claude", s, time.Now())
+	}
+	// Handled with 100 lines of history, 5 rows down the screen.
+	h := newHandledLimit(parse(screen(5)), 100)
+
+	tests := []struct {
+		name    string
+		screen  string
+		history int
+		covered bool
+	}{
+		{"same place", screen(5), 100, true},
+		{"scrolled up, history growing", screen(2), 103, true},
+		{"scrolled up, history full", screen(2), 100, true},
+		{"same words printed again below", screen(5) + screen(0), 100, false},
+		{"same words after more output", screen(8), 110, false},
+		{"other message", strings.Replace(screen(5), "4h", "5h", 1), 100, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := h.covers(parse(tt.screen), tt.history); got != tt.covered {
+				t.Errorf("covers() = %v, want %v", got, tt.covered)
+			}
+		})
+	}
+	if got, ok := parseHandled(h.String()); !ok || got != h {
+		t.Errorf("parseHandled(%q) = %v, %v", h.String(), got, ok)
+	}
+}
+
+func TestLaunchResolve(t *testing.T) {
+	t0 := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	type poll struct {
+		after time.Duration // since the monitor started
+		s     State
+		want  State
+	}
+	tests := []struct {
+		name  string
+		polls []poll
+	}{
+		{"CLI launching", []poll{{0, Exited, Starting}, {10 * time.Second, Starting, Starting}, {20 * time.Second, Idle, Idle}}},
+		{"unrecognised screen after startup", []poll{{0, Idle, Idle}, {time.Second, Starting, Working}}},
+		{"exit after startup", []poll{{0, Working, Working}, {time.Second, Exited, Exited}}},
+		{"at the shell when the monitor starts", []poll{{0, Exited, Starting}, {30 * time.Second, Exited, Starting}, {restartGrace, Exited, Exited}}},
+		{"died before the first poll", []poll{{30 * time.Second, Starting, Starting}, {restartGrace + time.Second, Exited, Exited}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := launch{since: t0}
+			for _, p := range tt.polls {
+				if got := l.resolve(p.s, t0.Add(p.after)); got != p.want {
+					t.Errorf("after %s: resolve(%s) = %s, want %s", p.after, p.s, got, p.want)
+				}
+			}
+		})
+	}
+}
+
+func TestStallWatch(t *testing.T) {
+	t0 := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	const after = 5 * time.Minute
+	type poll struct {
+		at     time.Duration // since the monitor started
+		screen string
+		s      State
+		want   State
+		began  bool
+	}
+	tests := []struct {
+		name  string
+		after time.Duration
+		polls []poll
+	}{
+		{"stalls after the timeout", after, []poll{
+			{0, "a", Working, Working, false},
+			{after - time.Second, "a", Working, Working, false},
+			{after, "a", Working, Stalled, true},
+		}},
+		{"acts once per stall", after, []poll{
+			{0, "a", Working, Working, false},
+			{after, "a", Working, Stalled, true},
+			{after + time.Minute, "a", Working, Stalled, false},
+			{after + 2*time.Minute, "b", Working, Working, false},
+			{2*after + 2*time.Minute, "b", Working, Stalled, true},
+		}},
+		{"progress resets the timer", after, []poll{
+			{0, "a", Working, Working, false},
+			{after - time.Second, "b", Working, Working, false},
+			{after + time.Minute, "b", Working, Working, false},
+		}},
+		{"waiting, idle and limited never stall", after, []poll{
+			{0, "a", Waiting, Waiting, false},
+			{after, "a", Waiting, Waiting, false},
+			{2 * after, "a", Idle, Idle, false},
+			{3 * after, "a", Limited, Limited, false},
+		}},
+		{"paused time does not count", after, []poll{
+			{0, "a", Working, Working, false},
+			{after - time.Second, "", Paused, Paused, false},
+			{after + time.Minute, "a", Working, Working, false},
+		}},
+		{"disabled", 0, []poll{
+			{0, "a", Working, Working, false},
+			{time.Hour, "a", Working, Working, false},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := stallWatch{after: tt.after, changed: t0}
+			for _, p := range tt.polls {
+				now := t0.Add(p.at)
+				if p.s != Paused {
+					w.see(p.screen, now)
+				}
+				got, began := w.resolve(p.s, now)
+				if got != p.want || began != p.began {
+					t.Errorf("after %s: resolve(%s) = %s, %v; want %s, %v", p.at, p.s, got, began, p.want, p.began)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/cpoulin/claude-swarm/internal/tmux"
+)
+
+// What the monitor does when a worker stalls (stall_action).
+const (
+	StallNone      = "none"      // mark the title and log it
+	StallNudge     = "nudge"     // also send stall_nudge
+	StallInterrupt = "interrupt" // press Escape first, then send stall_nudge
+)
+
+// progressKey reduces a screen to what counts as progress. The CLI's busy
+// line keeps ticking while an agent is stuck (spinner glyph, elapsed time,
+// token count), so only the letters of that line are kept.
+func progressKey(cli, content string) string {
+	working := patterns[cli].working
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if working == nil || !working.MatchString(line) {
+			continue
+		}
+		lines[i] = strings.Join(strings.FieldsFunc(line, func(r rune) bool {
+			return !unicode.IsLetter(r)
+		}), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// stallWatch decides when a working worker has stalled: its screen, reduced
+// by progressKey, has not changed for after (stall_timeout_secs; zero never
+// stalls).
+type stallWatch struct {
+	after   time.Duration
+	last    string
+	changed time.Time
+	stalled bool
+}
+
+// see records the screen of a poll and reports whether it changed.
+func (w *stallWatch) see(key string, now time.Time) bool {
+	if key == w.last {
+		return false
+	}
+	w.last, w.changed = key, now
+	return true
+}
+
+// resolve turns Working into Stalled once the screen has been still for
+// w.after. began is true on the first poll of each stall, which is the one
+// to act on. A paused worker's frozen screen does not count as still.
+func (w *stallWatch) resolve(s State, now time.Time) (_ State, began bool) {
+	switch {
+	case s == Paused:
+		w.changed = now
+	case s == Working && w.after > 0 && now.Sub(w.changed) >= w.after:
+		began = !w.stalled
+		w.stalled = true
+		return Stalled, began
+	}
+	w.stalled = false
+	return s, false
+}
+
+// nudge acts on a stalled worker according to action.
+func nudge(ctx context.Context, paneID, action, message string) error {
+	switch action {
+	case StallInterrupt:
+		if err := tmux.SendRaw(paneID, "Escape"); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+		fallthrough
+	case StallNudge:
+		return tmux.SendText(paneID, message)
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cpoulin/claude-swarm/internal/tmux"
+	"github.com/cpoulin/claude-swarm/internal/usagelimit"
+)
+
+// State is what a worker is doing, as read from its pane.
+type State string
+
+const (
+	Starting State = "starting" // CLI launched, no prompt seen yet
+	Working  State = "working"  // generating or running tools
+	Idle     State = "idle"     // at its input prompt, ready for a message
+	Waiting  State = "waiting"  // asking for approval or a choice
+	Limited  State = "limited"  // stopped by a usage limit
+	Exited   State = "exited"   // CLI gone, pane back at the shell
+	Paused   State = "paused"   // process tree stopped by claude-swarm pause
+	Stalled  State = "stalled"  // working, but no progress for stall_timeout_secs
+)
+
+// Pane options holding the last classified state of a worker pane, so other
+// commands can read it without watching the pane themselves.
+const (
+	StateOption      = "@swarm-state"
+	StateSinceOption = "@swarm-state-since"
+	ActivityOption   = "@swarm-activity"  // last time the screen changed
+	ResumeAtOption   = "@swarm-resume-at" // when a limited worker is resumed
+	PausedOption     = "@swarm-paused"    // when the worker was paused; unset otherwise
+	LimitOption      = "@swarm-limit"     // the usage-limit message last resumed from
+)
+
+// screenPatterns are the per-CLI markers of each state, matched against the
+// bottom of the screen. Waiting is checked before working, and working before
+// idle, because the input box stays visible while the CLI works.
+type screenPatterns struct {
+	waiting, working, idle *regexp.Regexp
+}
+
+var patterns = map[string]screenPatterns{
+	"claude": {
+		waiting: regexp.MustCompile(`(?i)(do you want to (proceed|make this edit|create)|trust (the files in )?this folder|❯ 1\. yes|enter to confirm)`),
+		working: regexp.MustCompile(`(?i)(esc to interrupt|esc to cancel)`),
+		idle:    regexp.MustCompile(`(?m)(\? for shortcuts|^\s*[│>] >?\s)`),
+	},
+	"gemini": {
+		waiting: regexp.MustCompile(`(?i)(allow execution|apply this change\?|waiting for user confirmation|do you want to proceed)`),
+		working: regexp.MustCompile(`(?i)\(esc to cancel`),
+		idle:    regexp.MustCompile(`(?i)type your message`),
+	},
+	"codex": {
+		waiting: regexp.MustCompile(`(?i)(allow command\?|would you like to (run|make)|yes, proceed|approve|\(y/n\))`),
+		working: regexp.MustCompile(`(?i)(esc to interrupt|working \()`),
+		idle:    regexp.MustCompile(`(?i)(⏎ send|enter to send|ctrl \+ c to quit)`),
+	},
+}
+
+// shells are the pane_current_command values of a pane whose CLI has exited.
+var shells = map[string]bool{"bash": true, "zsh": true, "sh": true, "fish": true, "dash": true, "ksh": true}
+
+// IsShell reports whether a pane's foreground command is a shell rather than
+// a CLI.
+func IsShell(command string) bool {
+	return shells[command]
+}
+
+// screenTail is how many non-blank lines at the bottom of the screen are
+// matched for the waiting, working and idle markers.
+const screenTail = 15
+
+// Classify reads the state of a worker from its screen and the pane's
+// foreground command, recognising usage limits with the built-in rules. A
+// screen with no known marker is Starting; callers that have already seen the
+// CLI running should read that as Working. content may carry the styling of
+// capture-pane -e, which helps tell a usage-limit message from one quoted in
+// a file or log the agent is showing.
+func Classify(cli, content, command string) State {
+	return classify(usagelimit.Default(), cli, content, command)
+}
+
+// classify is Classify with the usage-limit rules of limits.
+func classify(limits *usagelimit.Parser, cli, content, command string) State {
+	if limits.HasError(cli, limits.Screen(cli, content)) {
+		return Limited
+	}
+	return classifyScreen(cli, usagelimit.Strip(content), command)
+}
+
+// classifyScreen is Classify without the usage-limit check, for a plain
+// screen.
+func classifyScreen(cli, content, command string) State {
+	if shells[command] {
+		return Exited
+	}
+	p, ok := patterns[cli]
+	if !ok {
+		return Starting
+	}
+	tail := bottom(content, screenTail)
+	switch {
+	case p.waiting.MatchString(tail):
+		return Waiting
+	case p.working.MatchString(tail):
+		return Working
+	case p.idle.MatchString(tail):
+		return Idle
+	}
+	return Starting
+}
+
+// ClassifyPane is Classify for a pane captured with tmux.CapturePaneStyled,
+// whose history is its history_size at the capture, with the usage-limit
+// rules of limits. A usage-limit message the monitor has already resumed from
+// does not count; for a new one, the parsed message is returned too.
+func ClassifyPane(limits *usagelimit.Parser, paneID, cli, content string, history int, command string) (State, usagelimit.Result) {
+	s := classify(limits, cli, content, command)
+	if s != Limited {
+		return s, usagelimit.Result{}
+	}
+	r := limits.Parse(cli, limits.Screen(cli, content), time.Now())
+	if h, ok := readHandled(paneID); ok && h.covers(r, history) {
+		return classifyScreen(cli, usagelimit.Strip(content), command), usagelimit.Result{}
+	}
+	return Limited, r
+}
+
+// handledLimit is a usage-limit message the monitor resumed from, recorded in
+// the pane's @swarm-limit option as "<line>:<fingerprint>". line is where the
+// message was in the pane's scrollback (history_size plus its row), so the
+// same words printed again after the resume are a new message.
+type handledLimit struct {
+	line        int
+	fingerprint string
+}
+
+func newHandledLimit(r usagelimit.Result, history int) handledLimit {
+	return handledLimit{line: history + r.Row, fingerprint: fingerprint(r.Line)}
+}
+
+// covers reports whether r, parsed from a capture at history, is the handled
+// message. A message only moves up the scrollback, and does so on screen too
+// once the history is full, so it is at or above the recorded line.
+func (h handledLimit) covers(r usagelimit.Result, history int) bool {
+	return r.Found && fingerprint(r.Line) == h.fingerprint && history+r.Row <= h.line
+}
+
+func (h handledLimit) String() string {
+	return fmt.Sprintf("%d:%s", h.line, h.fingerprint)
+}
+
+func fingerprint(line string) string {
+	f := fnv.New64a()
+	f.Write([]byte(line))
+	return strconv.FormatUint(f.Sum64(), 16)
+}
+
+// markHandled records the usage-limit message on the pane's screen as the one
+// resumed from, after dropping the pane's scrollback if clear is set.
+func markHandled(limits *usagelimit.Parser, paneID, cli string, clear bool) {
+	if clear {
+		_ = tmux.ClearHistory(paneID)
+	}
+	content, history, err := tmux.CapturePaneStyled(paneID)
+	if err != nil {
+		return
+	}
+	if r := limits.Parse(cli, limits.Screen(cli, content), time.Now()); r.Found {
+		_ = tmux.SetPaneOption(paneID, LimitOption, newHandledLimit(r, history).String())
+	}
+}
+
+func readHandled(paneID string) (handledLimit, bool) {
+	out, err := tmux.DisplayFormat(paneID, "#{"+LimitOption+"}")
+	if err != nil {
+		return handledLimit{}, false
+	}
+	return parseHandled(out)
+}
+
+// parseHandled reads the @swarm-limit option written by handledLimit.String.
+func parseHandled(v string) (handledLimit, bool) {
+	line, fp, ok := strings.Cut(v, ":")
+	n, err := strconv.Atoi(line)
+	if !ok || err != nil {
+		return handledLimit{}, false
+	}
+	return handledLimit{line: n, fingerprint: fp}, true
+}
+
+// bottom returns the last n non-blank lines of content.
+func bottom(content string, n int) string {
+	lines := strings.Split(strings.TrimRight(content, "\n "), "\n")
+	kept := make([]string, 0, n)
+	for i := len(lines) - 1; i >= 0 && len(kept) < n; i-- {
+		if strings.TrimSpace(lines[i]) != "" {
+			kept = append(kept, lines[i])
+		}
+	}
+	for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+		kept[i], kept[j] = kept[j], kept[i]
+	}
+	return strings.Join(kept, "\n")
+}
+
+// Observe captures a pane and classifies it with the built-in usage-limit
+// rules; monitors, which also apply limit_rules, record what they see for
+// ReadPaneStatus. A paused pane is Paused: its frozen screen says nothing
+// about the worker.
+func Observe(paneID, cli string) (State, error) {
+	command, paused, err := foreground(paneID)
+	if err != nil {
+		return "", err
+	}
+	if paused {
+		return Paused, nil
+	}
+	content, history, err := tmux.CapturePaneStyled(paneID)
+	if err != nil {
+		return "", err
+	}
+	s, _ := ClassifyPane(usagelimit.Default(), paneID, cli, content, history, command)
+	return s, nil
+}
+
+// foreground returns the pane's foreground command and whether it is paused.
+func foreground(paneID string) (command string, paused bool, err error) {
+	out, err := tmux.DisplayFormat(paneID, "#{pane_current_command}\t#{"+PausedOption+"}")
+	if err != nil {
+		return "", false, err
+	}
+	command, since, _ := strings.Cut(out, "\t")
+	return command, since != "", nil
+}
+
+// MarkPaused records on the pane that its process tree was stopped (or, with
+// paused false, resumed), so monitors leave the frozen screen alone.
+func MarkPaused(paneID string, paused bool) error {
+	if !paused {
+		return tmux.UnsetPaneOption(paneID, PausedOption)
+	}
+	return tmux.SetPaneOption(paneID, PausedOption, strconv.FormatInt(time.Now().Unix(), 10))
+}
+
+// IsPaused reports whether the pane is marked paused.
+func IsPaused(paneID string) bool {
+	_, paused, err := foreground(paneID)
+	return err == nil && paused
+}
+
+// PaneStatus is what a worker's monitor has recorded on its pane.
+type PaneStatus struct {
+	State    State
+	Since    time.Time
+	Activity time.Time
+	ResumeAt time.Time // zero unless State is Limited
+}
+
+// ReadPaneStatus returns what the monitor last recorded on a pane. ok is
+// false if no monitor has recorded a state there.
+func ReadPaneStatus(paneID string) (st PaneStatus, ok bool) {
+	format := fmt.Sprintf("#{%s}\t#{%s}\t#{%s}\t#{%s}\t#{%s}",
+		StateOption, StateSinceOption, ActivityOption, ResumeAtOption, PausedOption)
+	out, err := tmux.DisplayFormat(paneID, format)
+	if err != nil {
+		return st, false
+	}
+	f := strings.Split(out, "\t")
+	if len(f) != 5 {
+		return st, false
+	}
+	if f[4] != "" {
+		// Paused wins over whatever a monitor recorded before the pause.
+		return PaneStatus{State: Paused, Since: unixOption(f[4]), Activity: unixOption(f[2])}, true
+	}
+	if f[0] == "" {
+		return st, false
+	}
+	st.State = State(f[0])
+	st.Since = unixOption(f[1])
+	st.Activity = unixOption(f[2])
+	if st.State == Limited {
+		st.ResumeAt = unixOption(f[3])
+	}
+	return st, true
+}
+
+func unixOption(v string) time.Time {
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(n, 0)
+}
+
+// recordState stores s on the pane for ReadPaneStatus.
+func recordState(paneID string, s State, since time.Time) {
+	_ = tmux.SetPaneOption(paneID, StateOption, string(s))
+	_ = tmux.SetPaneOption(paneID, StateSinceOption, strconv.FormatInt(since.Unix(), 10))
+}
+
+// recordTime stores a time option on the pane.
+func recordTime(paneID, option string, t time.Time) {
+	_ = tmux.SetPaneOption(paneID, option, strconv.FormatInt(t.Unix(), 10))
+}
+
+// WaitReady polls a pane until the CLI is idle at its input prompt. It
+// reports false if timeout elapses or ctx is cancelled first.
+func WaitReady(ctx context.Context, paneID, cli string, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(time.Second):
+		}
+		s, err := Observe(paneID, cli)
+		if err != nil {
+			return false
+		}
+		if s == Idle {
+			return true
+		}
+	}
+	return false
+}
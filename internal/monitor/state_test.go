@@ -0,0 +1,44 @@
+package monitor
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name    string
+		cli     string
+		content string
+		command string
+		want    State
+	}{
+		{"shell", "claude", "$ claude\n$ ", "bash", Exited},
+		{"blank", "claude", "", "claude", Starting},
+		{"claude idle", "claude", "╭────╮\n│ >   │\n╰────╯\n  ? for shortcuts\n", "claude", Idle},
+		{"claude working", "claude", "✻ Thinking… (12s · esc to interrupt)\n╭────╮\n│ >   │\n╰────╯\n  ? for shortcuts\n", "node", Working},
+		{"claude approval", "claude", "Do you want to proceed?\n❯ 1. Yes\n  2. No\n", "claude", Waiting},
+		{"claude trust", "claude", "Do you trust the files in this folder?\n❯ 1. Yes, proceed\n", "claude", Waiting},
+		{"claude limit", "claude", "You have exceeded your usage limit. Try again after 15:00 UTC.\n$ ", "bash", Limited},
+		{"claude quoted limit", "claude", "  ⎿  \x1b[2mYou have exceeded your usage limit.\x1b[22m\n  ? for shortcuts\n", "claude", Idle},
+		{"gemini idle", "gemini", "> Type your message or @path/to/file\n", "node", Idle},
+		{"gemini working", "gemini", "⠋ Reading files (esc to cancel, 3s)\n> Type your message\n", "node", Working},
+		{"gemini approval", "gemini", "Allow execution of: 'rm -rf build'?\n● Yes, allow once\n", "node", Waiting},
+		{"codex idle", "codex", "▌ Ask Codex to do anything\n ⏎ send   ⇧⏎ newline\n", "codex", Idle},
+		{"codex working", "codex", "• Working (5s • Esc to interrupt)\n⏎ send\n", "codex", Working},
+		{"codex approval", "codex", "Would you like to run the following command?\n  Yes, proceed (y)\n", "codex", Waiting},
+		{"old prompt scrolled away", "claude", "? for shortcuts\n" + lines(20, "output line"), "claude", Starting},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.cli, tt.content, tt.command); got != tt.want {
+				t.Errorf("Classify() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func lines(n int, s string) string {
+	out := ""
+	for i := 0; i < n; i++ {
+		out += s + "\n"
+	}
+	return out
+}
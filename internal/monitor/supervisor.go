@@ -0,0 +1,69 @@
+package monitor
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/cpoulin/claude-swarm/internal/config"
+	"github.com/cpoulin/claude-swarm/internal/tmux"
+	"github.com/cpoulin/claude-swarm/internal/usagelimit"
+)
+
+// Supervise keeps one Watch running per target returned by list. The list is
+// re-read every monitor interval, so workers added, removed or relaunched
+// after startup are picked up. It returns when ctx is cancelled or the
+// session no longer exists.
+func Supervise(ctx context.Context, cfg *config.Config, limits *usagelimit.Parser, session string, list func() ([]Target, error), w io.Writer) {
+	type watch struct {
+		target Target
+		cancel context.CancelFunc
+	}
+	watches := make(map[string]watch)
+	defer func() {
+		for _, wt := range watches {
+			wt.cancel()
+		}
+	}()
+
+	sync := func() {
+		targets, err := list()
+		if err != nil {
+			return
+		}
+		seen := make(map[string]bool, len(targets))
+		for _, t := range targets {
+			seen[t.PaneID] = true
+			if wt, ok := watches[t.PaneID]; ok {
+				if wt.target == t {
+					continue
+				}
+				wt.cancel()
+			}
+			wctx, cancel := context.WithCancel(ctx)
+			watches[t.PaneID] = watch{target: t, cancel: cancel}
+			go Watch(wctx, cfg, limits, session, t, w)
+		}
+		for id, wt := range watches {
+			if !seen[id] {
+				wt.cancel()
+				delete(watches, id)
+			}
+		}
+	}
+
+	sync()
+	ticker := time.NewTicker(time.Duration(cfg.MonitorInterval) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !tmux.HasSession(session) {
+			return
+		}
+		sync()
+	}
+}
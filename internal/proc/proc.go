@@ -0,0 +1,83 @@
+// Package proc finds and signals the process tree running in a pane.
+package proc
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Tree returns pid and all of its descendants, parents before children.
+func Tree(pid int) ([]int, error) {
+	out, err := exec.Command("ps", "-A", "-o", "pid=,ppid=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("ps: %w", err)
+	}
+	return descendants(string(out), pid), nil
+}
+
+// descendants walks "pid ppid" lines breadth-first from root.
+func descendants(ps string, root int) []int {
+	children := map[int][]int{}
+	for _, line := range strings.Split(ps, "\n") {
+		f := strings.Fields(line)
+		if len(f) != 2 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(f[0])
+		ppid, err2 := strconv.Atoi(f[1])
+		if err1 != nil || err2 != nil || pid == ppid {
+			continue
+		}
+		children[ppid] = append(children[ppid], pid)
+	}
+	tree := []int{root}
+	for i := 0; i < len(tree); i++ {
+		tree = append(tree, children[tree[i]]...)
+	}
+	return tree
+}
+
+// Stop suspends the descendants of pid with SIGSTOP, parents before their
+// children. pid itself, the pane's shell, keeps running: tmux continues a
+// stopped pane process at once. It returns the number of processes stopped.
+func Stop(pid int) (int, error) {
+	tree, err := Tree(pid)
+	if err != nil {
+		return 0, err
+	}
+	return signal(tree[1:], syscall.SIGSTOP)
+}
+
+// Cont resumes the descendants of pid with SIGCONT, children first, undoing
+// Stop. It returns the number of processes resumed.
+func Cont(pid int) (int, error) {
+	tree, err := Tree(pid)
+	if err != nil {
+		return 0, err
+	}
+	tree = tree[1:]
+	for i, j := 0, len(tree)-1; i < j; i, j = i+1, j-1 {
+		tree[i], tree[j] = tree[j], tree[i]
+	}
+	return signal(tree, syscall.SIGCONT)
+}
+
+// signal sends sig to every pid in order. Processes that exited in the
+// meantime are skipped.
+func signal(pids []int, sig syscall.Signal) (int, error) {
+	n := 0
+	for _, pid := range pids {
+		if err := syscall.Kill(pid, sig); err != nil {
+			if errors.Is(err, syscall.ESRCH) {
+				continue
+			}
+			return n, fmt.Errorf("signalling process %d: %w", pid, err)
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,24 @@
+package proc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDescendants(t *testing.T) {
+	ps := `
+    1     0
+  100     1
+  101   100
+  102   100
+  200     1
+  103   101
+  104   102
+`
+	if got, want := descendants(ps, 100), []int{100, 101, 102, 103, 104}; !reflect.DeepEqual(got, want) {
+		t.Errorf("descendants(100) = %v, want %v", got, want)
+	}
+	if got, want := descendants(ps, 200), []int{200}; !reflect.DeepEqual(got, want) {
+		t.Errorf("descendants(200) = %v, want %v", got, want)
+	}
+}
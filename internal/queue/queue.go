@@ -0,0 +1,328 @@
+// Package queue parses a markdown task backlog and persists how far a swarm
+// got through it, so a restarted dispatcher continues where it stopped.
+package queue
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cpoulin/claude-swarm/internal/state"
+)
+
+// Status is the dispatch state of a task.
+type Status string
+
+const (
+	Pending Status = "pending"
+	Running Status = "running"
+	Done    Status = "done"
+)
+
+// Task is one entry of the backlog.
+type Task struct {
+	ID          string    `json:"id"`
+	Num         int       `json:"num"`
+	Title       string    `json:"title"`
+	Prompt      string    `json:"prompt"`
+	Status      Status    `json:"status"`
+	Worker      int       `json:"worker,omitempty"`
+	PaneID      string    `json:"pane_id,omitempty"`
+	Branch      string    `json:"branch,omitempty"`
+	StartCommit string    `json:"start_commit,omitempty"`
+	StartedAt   time.Time `json:"started_at,omitzero"`
+	DoneAt      time.Time `json:"done_at,omitzero"`
+	DoneBy      string    `json:"done_by,omitempty"`
+}
+
+// Queue is the persisted progress of a session through its backlog.
+type Queue struct {
+	Session string `json:"session"`
+	Source  string `json:"source"`
+	// SwarmCreatedAt identifies the swarm the running tasks were sent to;
+	// tmux reuses pane IDs once its server restarts.
+	SwarmCreatedAt time.Time `json:"swarm_created_at,omitzero"`
+	Tasks          []Task    `json:"tasks"`
+}
+
+var (
+	checkRe   = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+(.*)$`)
+	headingRe = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+)
+
+// Parse reads a markdown backlog. Checklist items ("- [ ] task") are tasks,
+// with indented lines below an item added to its prompt; checked items count
+// as done. A file without a checklist is split into sections at the heading
+// level that repeats (usually "##"), each heading plus its body being one task.
+func Parse(r io.Reader) ([]Task, error) {
+	var lines []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	tasks := parseChecklist(lines)
+	if len(tasks) == 0 {
+		tasks = parseSections(lines)
+	}
+	seen := map[string]int{}
+	for i := range tasks {
+		t := &tasks[i]
+		t.Prompt = strings.TrimSpace(t.Prompt)
+		t.ID = taskID(t.Prompt, seen)
+	}
+	return tasks, nil
+}
+
+func parseChecklist(lines []string) []Task {
+	var tasks []Task
+	indent := -1
+	for _, line := range lines {
+		if m := checkRe.FindStringSubmatch(line); m != nil {
+			t := Task{Title: strings.TrimSpace(m[2]), Prompt: m[2], Status: Pending}
+			if m[1] != " " {
+				t.Status = Done
+			}
+			tasks = append(tasks, t)
+			indent = len(line) - len(strings.TrimLeft(line, " \t"))
+			continue
+		}
+		if len(tasks) == 0 || indent < 0 {
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			tasks[len(tasks)-1].Prompt += "\n"
+			continue
+		}
+		if len(line)-len(strings.TrimLeft(line, " \t")) <= indent {
+			indent = -1 // back at the list's level: the item has ended
+			continue
+		}
+		tasks[len(tasks)-1].Prompt += "\n" + strings.TrimSpace(line)
+	}
+	return tasks
+}
+
+func parseSections(lines []string) []Task {
+	counts := map[int]int{}
+	for _, line := range lines {
+		if m := headingRe.FindStringSubmatch(line); m != nil {
+			counts[len(m[1])]++
+		}
+	}
+	level := 0
+	for l := 1; l <= 6; l++ {
+		if counts[l] > 1 {
+			level = l
+			break
+		}
+		if level == 0 && counts[l] > 0 {
+			level = l
+		}
+	}
+	if level == 0 {
+		return nil
+	}
+
+	var tasks []Task
+	in := false
+	for _, line := range lines {
+		if m := headingRe.FindStringSubmatch(line); m != nil && len(m[1]) <= level {
+			in = len(m[1]) == level
+			if in {
+				tasks = append(tasks, Task{Title: m[2], Prompt: m[2], Status: Pending})
+			}
+			continue
+		}
+		if in {
+			tasks[len(tasks)-1].Prompt += "\n" + line
+		}
+	}
+	return tasks
+}
+
+// taskID derives a stable ID from the prompt, numbering repeats.
+func taskID(prompt string, seen map[string]int) string {
+	sum := sha1.Sum([]byte(prompt))
+	id := hex.EncodeToString(sum[:])[:10]
+	seen[id]++
+	if n := seen[id]; n > 1 {
+		id = fmt.Sprintf("%s-%d", id, n)
+	}
+	return id
+}
+
+// Merge folds freshly parsed tasks into the queue: known tasks keep their
+// progress, new ones are appended, and pending tasks that were deleted from
+// the backlog are dropped. A task checked off in the file is marked done.
+func (q *Queue) Merge(tasks []Task) {
+	inFile := map[string]Task{}
+	for _, t := range tasks {
+		inFile[t.ID] = t
+	}
+	kept := q.Tasks[:0]
+	next := 1
+	for _, t := range q.Tasks {
+		ft, ok := inFile[t.ID]
+		if !ok && t.Status == Pending {
+			continue
+		}
+		if ok && ft.Status == Done && t.Status == Pending {
+			t.Status = Done
+		}
+		kept = append(kept, t)
+		if t.Num >= next {
+			next = t.Num + 1
+		}
+	}
+	q.Tasks = kept
+	for _, t := range tasks {
+		if q.Task(t.ID) != nil {
+			continue
+		}
+		t.Num = next
+		next++
+		q.Tasks = append(q.Tasks, t)
+	}
+}
+
+// Task returns the task with the given ID, or nil.
+func (q *Queue) Task(id string) *Task {
+	for i := range q.Tasks {
+		if q.Tasks[i].ID == id {
+			return &q.Tasks[i]
+		}
+	}
+	return nil
+}
+
+// RunningOn returns the task running on worker, or nil.
+func (q *Queue) RunningOn(worker int) *Task {
+	for i := range q.Tasks {
+		if q.Tasks[i].Status == Running && q.Tasks[i].Worker == worker {
+			return &q.Tasks[i]
+		}
+	}
+	return nil
+}
+
+// Claim marks the next pending task as running on worker and returns it, or
+// nil when nothing is left.
+func (q *Queue) Claim(worker int, paneID string) *Task {
+	for i := range q.Tasks {
+		t := &q.Tasks[i]
+		if t.Status != Pending {
+			continue
+		}
+		t.Status = Running
+		t.Worker = worker
+		t.PaneID = paneID
+		t.StartedAt = time.Now().UTC()
+		return t
+	}
+	return nil
+}
+
+// Complete marks the task done, recording how completion was detected.
+func (q *Queue) Complete(id, by string) {
+	if t := q.Task(id); t != nil {
+		t.Status = Done
+		t.DoneAt = time.Now().UTC()
+		t.DoneBy = by
+	}
+}
+
+// Requeue puts running tasks back in line when the pane they were sent to is
+// gone or now belongs to someone else. paneOf returns a worker's current pane
+// ID ("" if it has none). It returns the number of tasks requeued.
+func (q *Queue) Requeue(paneOf func(worker int) string) int {
+	n := 0
+	for i := range q.Tasks {
+		t := &q.Tasks[i]
+		if t.Status == Running && paneOf(t.Worker) != t.PaneID {
+			t.Status = Pending
+			t.Worker, t.PaneID, t.StartCommit = 0, "", ""
+			t.StartedAt = time.Time{}
+			n++
+		}
+	}
+	return n
+}
+
+// Counts returns the number of pending, running and done tasks.
+func (q *Queue) Counts() (pending, running, done int) {
+	for _, t := range q.Tasks {
+		switch t.Status {
+		case Pending:
+			pending++
+		case Running:
+			running++
+		case Done:
+			done++
+		}
+	}
+	return pending, running, done
+}
+
+// Path returns the queue progress file of session.
+func Path(session string) (string, error) {
+	dir, err := state.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, session+".queue.json"), nil
+}
+
+// Load reads the saved progress of session, or returns an empty queue if
+// none was saved yet.
+func Load(session string) (*Queue, error) {
+	path, err := Path(session)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Queue{Session: session}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading queue: %w", err)
+	}
+	var q Queue
+	if err := json.Unmarshal(data, &q); err != nil {
+		return nil, fmt.Errorf("parsing queue %s: %w", path, err)
+	}
+	return &q, nil
+}
+
+// Save atomically writes the queue progress.
+func Save(q *Queue) error {
+	path, err := Path(q.Session)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating state dir: %w", err)
+	}
+	data, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing queue: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,130 @@
+package queue
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseChecklist(t *testing.T) {
+	const doc = `# Backlog
+
+Some intro text.
+
+- [ ] Fix the flaky queue test
+- [x] Bump Go version
+- [ ] Add a --json flag to status
+  Keep the field names snake_case.
+  Document it in the README.
+
+Notes that are not a task.
+`
+	tasks, err := Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 3 {
+		t.Fatalf("got %d tasks, want 3", len(tasks))
+	}
+	if tasks[0].Prompt != "Fix the flaky queue test" || tasks[0].Status != Pending {
+		t.Errorf("task 1 = %+v", tasks[0])
+	}
+	if tasks[1].Status != Done {
+		t.Errorf("checked item status = %s, want done", tasks[1].Status)
+	}
+	want := "Add a --json flag to status\nKeep the field names snake_case.\nDocument it in the README."
+	if tasks[2].Prompt != want {
+		t.Errorf("task 3 prompt = %q, want %q", tasks[2].Prompt, want)
+	}
+	if tasks[2].Title != "Add a --json flag to status" {
+		t.Errorf("task 3 title = %q", tasks[2].Title)
+	}
+}
+
+func TestParseSections(t *testing.T) {
+	const doc = `# Backlog
+
+## Fix login
+
+The session cookie expires too early.
+
+### Details
+See issue 12.
+
+## Add dark mode
+`
+	tasks, err := Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("got %d tasks, want 2: %+v", len(tasks), tasks)
+	}
+	want := "Fix login\n\nThe session cookie expires too early.\n\n### Details\nSee issue 12."
+	if tasks[0].Prompt != want {
+		t.Errorf("task 1 prompt = %q, want %q", tasks[0].Prompt, want)
+	}
+	if tasks[1].Title != "Add dark mode" || tasks[1].Prompt != "Add dark mode" {
+		t.Errorf("task 2 = %+v", tasks[1])
+	}
+}
+
+func TestParseDuplicateIDs(t *testing.T) {
+	tasks, err := Parse(strings.NewReader("- [ ] same\n- [ ] same\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tasks[0].ID == tasks[1].ID {
+		t.Errorf("duplicate tasks share ID %s", tasks[0].ID)
+	}
+}
+
+func TestMergeKeepsProgress(t *testing.T) {
+	parse := func(doc string) []Task {
+		tasks, err := Parse(strings.NewReader(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tasks
+	}
+	q := &Queue{Session: "s"}
+	q.Merge(parse("- [ ] a\n- [ ] b\n- [ ] c\n"))
+	a := q.Claim(1, "%1")
+	q.Complete(a.ID, "sentinel")
+	q.Claim(2, "%2")
+
+	// b is running, c was deleted, d is new.
+	q.Merge(parse("- [ ] a\n- [ ] b\n- [ ] d\n"))
+	var got []string
+	for _, task := range q.Tasks {
+		got = append(got, task.Prompt+":"+string(task.Status))
+	}
+	want := "a:done b:running d:pending"
+	if strings.Join(got, " ") != want {
+		t.Errorf("tasks = %v, want %s", got, want)
+	}
+	if a, b := q.Tasks[0], q.Tasks[1]; a.Num != 1 || b.Num != 2 {
+		t.Errorf("task numbers changed: a=%d b=%d", a.Num, b.Num)
+	}
+}
+
+func TestRequeue(t *testing.T) {
+	q := &Queue{Session: "s"}
+	tasks, _ := Parse(strings.NewReader("- [ ] a\n- [ ] b\n"))
+	q.Merge(tasks)
+	q.Claim(1, "%1")
+	q.Claim(2, "%2")
+
+	panes := map[int]string{1: "%1", 2: "%9"}
+	if n := q.Requeue(func(w int) string { return panes[w] }); n != 1 {
+		t.Fatalf("requeued %d, want 1", n)
+	}
+	if q.RunningOn(1) == nil {
+		t.Error("task on unchanged pane was requeued")
+	}
+	if b := q.Tasks[1]; b.Status != Pending || b.Worker != 0 {
+		t.Errorf("task on replaced pane = %+v, want pending", b)
+	}
+	if next := q.Claim(3, "%3"); next == nil || next.Prompt != "b" {
+		t.Errorf("next claim = %+v, want b", next)
+	}
+}
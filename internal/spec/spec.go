@@ -0,0 +1,121 @@
+// Package spec loads declarative swarm definitions (swarm.yaml) that list
+// every worker with its own CLI, model, flags, environment and prompt.
+package spec
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// FileNames are the spec files looked up in the repository root, in order.
+var FileNames = []string{"swarm.yaml", "swarm.yml", ".swarm.yaml"}
+
+// Worker describes one agent. In a spec file, Count repeats the entry.
+type Worker struct {
+	CLI    string            `yaml:"cli"`
+	Model  string            `yaml:"model"`
+	Flags  string            `yaml:"flags"`
+	Env    map[string]string `yaml:"env"`
+	Dir    string            `yaml:"dir"`
+	Label  string            `yaml:"label"`
+	Prompt string            `yaml:"prompt"`
+	Count  int               `yaml:"count"`
+}
+
+// Spec is a parsed swarm.yaml.
+type Spec struct {
+	Session    string            `yaml:"session"`
+	BaseBranch string            `yaml:"base_branch"`
+	CLIFlags   map[string]string `yaml:"cli_flags"` // default flags per CLI name
+	Defaults   Worker            `yaml:"defaults"`
+	Workers    []Worker          `yaml:"workers"`
+}
+
+// Name returns the worker's CLI in "cli" or "cli:model" form.
+func (w Worker) Name() string {
+	if w.Model == "" {
+		return w.CLI
+	}
+	return w.CLI + ":" + w.Model
+}
+
+// Load reads and validates a spec file.
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading spec: %w", err)
+	}
+	var s Spec
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("parsing spec %s: %w", path, err)
+	}
+	if len(s.Workers) == 0 {
+		return nil, fmt.Errorf("spec %s lists no workers", path)
+	}
+	return &s, nil
+}
+
+// Find returns the first spec file present in dir.
+func Find(dir string) (string, bool) {
+	for _, name := range FileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// Expand returns one Worker per agent: Count is unrolled, unset fields are
+// taken from Defaults, and workers without flags get their CLI's cli_flags.
+// Env maps are merged, the worker's own entries winning.
+func (s *Spec) Expand() []Worker {
+	var workers []Worker
+	for _, w := range s.Workers {
+		w = merge(s.Defaults, w)
+		if w.Flags == "" {
+			w.Flags = s.CLIFlags[w.CLI]
+		}
+		n := max(w.Count, 1)
+		w.Count = 0
+		for range n {
+			workers = append(workers, w)
+		}
+	}
+	return workers
+}
+
+func merge(def, w Worker) Worker {
+	if w.CLI == "" {
+		w.CLI = def.CLI
+		if w.Model == "" {
+			w.Model = def.Model
+		}
+	}
+	if w.Flags == "" && w.CLI == def.CLI {
+		w.Flags = def.Flags
+	}
+	if w.Dir == "" {
+		w.Dir = def.Dir
+	}
+	if w.Prompt == "" {
+		w.Prompt = def.Prompt
+	}
+	if len(def.Env) > 0 {
+		env := make(map[string]string, len(def.Env)+len(w.Env))
+		for k, v := range def.Env {
+			env[k] = v
+		}
+		for k, v := range w.Env {
+			env[k] = v
+		}
+		w.Env = env
+	}
+	return w
+}
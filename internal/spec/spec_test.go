@@ -0,0 +1,131 @@
+package spec
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadExpand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "swarm.yaml")
+	const doc = `
+session: features
+cli_flags:
+  claude: --dangerously-skip-permissions
+defaults:
+  cli: claude
+  env: {LOG_LEVEL: debug}
+workers:
+  - count: 2
+  - cli: gemini
+    model: gemini-3-flash
+    dir: web
+    label: frontend
+  - cli: codex
+    flags: --full-auto
+    env: {LOG_LEVEL: info, CI: "1"}
+    prompt: fix the flaky tests
+`
+	if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.Expand()
+	want := []Worker{
+		{CLI: "claude", Flags: "--dangerously-skip-permissions", Env: map[string]string{"LOG_LEVEL": "debug"}},
+		{CLI: "claude", Flags: "--dangerously-skip-permissions", Env: map[string]string{"LOG_LEVEL": "debug"}},
+		{CLI: "gemini", Model: "gemini-3-flash", Dir: "web", Label: "frontend", Env: map[string]string{"LOG_LEVEL": "debug"}},
+		{CLI: "codex", Flags: "--full-auto", Prompt: "fix the flaky tests", Env: map[string]string{"LOG_LEVEL": "info", "CI": "1"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expand() =\n%+v\nwant\n%+v", got, want)
+	}
+	if s.Session != "features" {
+		t.Errorf("Session = %q, want features", s.Session)
+	}
+}
+
+func TestLoadRejectsUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "swarm.yaml")
+	if err := os.WriteFile(path, []byte("workers:\n  - cli: claude\n    modle: opus\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load accepted a misspelled field")
+	}
+}
+
+func TestAssignTasks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.yaml")
+	const doc = `
+- Write the README
+- worker: 3
+  prompt: Fix issue 42 on {{.Branch}}
+- Add tests
+`
+	if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tasks, err := LoadTasks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	workers := []Worker{{CLI: "claude", Prompt: "from spec"}, {CLI: "claude"}, {CLI: "codex"}, {CLI: "claude"}}
+	if err := AssignTasks(workers, tasks); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"from spec", "Write the README", "Fix issue 42 on {{.Branch}}", "Add tests"}
+	for i, w := range workers {
+		if w.Prompt != want[i] {
+			t.Errorf("worker %d prompt = %q, want %q", i+1, w.Prompt, want[i])
+		}
+	}
+
+	if err := AssignTasks(workers[:1], []Task{{Prompt: "extra"}}); err == nil {
+		t.Error("AssignTasks accepted more tasks than free workers")
+	}
+}
+
+func TestRenderPrompt(t *testing.T) {
+	got, err := RenderPrompt("worker {{.Index}} on {{.Branch}} in {{.Worktree}}",
+		PromptData{Index: 2, Branch: "swarm/s/main/worker-2", Worktree: "/tmp/wt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "worker 2 on swarm/s/main/worker-2 in /tmp/wt"; got != want {
+		t.Errorf("RenderPrompt = %q, want %q", got, want)
+	}
+	if _, err := RenderPrompt("{{.Nope}}", PromptData{}); err == nil {
+		t.Error("RenderPrompt accepted an unknown field")
+	}
+}
+
+func TestRenderSpecPrompt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "swarm.yaml")
+	const doc = `
+defaults:
+  cli: claude
+  prompt: 'Commit on {{.Branch}} as {{.Label}}; keep {{"{{"}} literal'
+workers:
+  - label: api
+`
+	if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := s.Expand()[0]
+	got, err := RenderPrompt(w.Prompt, PromptData{Index: 1, Label: w.Label, Branch: "swarm/s/main/worker-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Commit on swarm/s/main/worker-1 as api; keep {{ literal"; got != want {
+		t.Errorf("RenderPrompt = %q, want %q", got, want)
+	}
+}
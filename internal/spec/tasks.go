@@ -0,0 +1,99 @@
+package spec
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"text/template"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Task assigns an initial prompt to a worker. Worker is the 1-based worker
+// number; zero means "the next worker without a prompt".
+type Task struct {
+	Worker int    `yaml:"worker"`
+	Prompt string `yaml:"prompt"`
+}
+
+// UnmarshalYAML accepts either a bare string (the prompt) or a mapping.
+func (t *Task) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		t.Prompt = node.Value
+		return nil
+	}
+	type plain Task
+	return node.Decode((*plain)(t))
+}
+
+// LoadTasks reads a tasks file: either a list of tasks or a mapping with a
+// "tasks" list.
+func LoadTasks(path string) ([]Task, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading tasks: %w", err)
+	}
+	var list []Task
+	if err := yaml.Unmarshal(data, &list); err == nil {
+		return list, nil
+	}
+	var doc struct {
+		Tasks []Task `yaml:"tasks"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing tasks %s: %w", path, err)
+	}
+	return doc.Tasks, nil
+}
+
+// AssignTasks sets the prompts of workers from tasks: numbered tasks go to
+// their worker, the rest fill workers that still have no prompt, in order.
+func AssignTasks(workers []Worker, tasks []Task) error {
+	var queue []string
+	for _, t := range tasks {
+		if t.Worker == 0 {
+			queue = append(queue, t.Prompt)
+			continue
+		}
+		if t.Worker < 1 || t.Worker > len(workers) {
+			return fmt.Errorf("task for worker %d, but the swarm has %d workers", t.Worker, len(workers))
+		}
+		workers[t.Worker-1].Prompt = t.Prompt
+	}
+	for i := range workers {
+		if len(queue) == 0 {
+			break
+		}
+		if workers[i].Prompt == "" {
+			workers[i].Prompt, queue = queue[0], queue[1:]
+		}
+	}
+	if len(queue) > 0 {
+		return fmt.Errorf("%d task(s) left over — every worker already has a prompt", len(queue))
+	}
+	return nil
+}
+
+// PromptData is what prompt templates can refer to, e.g. "{{.Branch}}".
+type PromptData struct {
+	Index    int
+	Session  string
+	CLI      string
+	Label    string
+	Branch   string
+	Base     string
+	Worktree string
+}
+
+// RenderPrompt expands a prompt template.
+func RenderPrompt(prompt string, data PromptData) (string, error) {
+	tmpl, err := template.New("prompt").Option("missingkey=error").Parse(prompt)
+	if err != nil {
+		return "", fmt.Errorf("parsing prompt template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("rendering prompt: %w", err)
+	}
+	return buf.String(), nil
+}
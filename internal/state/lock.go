@@ -0,0 +1,79 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// Lock takes the exclusive lock of session, waiting while another
+// claude-swarm process is changing the same swarm; onWait, if not nil, is
+// called before waiting. Call unlock to release the lock; it is also released
+// if the process dies.
+func Lock(session string, onWait func()) (unlock func(), err error) {
+	f, err := openLock(session)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		if onWait != nil {
+			onWait()
+		}
+		if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("locking session %q: %w", session, err)
+		}
+	}
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
+
+// TryLock is Lock without the wait: ok is false if another process holds the
+// lock.
+func TryLock(session string) (unlock func(), ok bool, err error) {
+	f, err := openLock(session)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("locking session %q: %w", session, err)
+	}
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, true, nil
+}
+
+// MonitorLock is the name to lock for running a session's monitors, which
+// only one process may do at a time.
+func MonitorLock(session string) string {
+	return session + ".monitor"
+}
+
+// DaemonLock is the name the monitor daemon of a session holds for as long as
+// it runs, so a second daemon never starts or touches the first one's pidfile.
+func DaemonLock(session string) string {
+	return session + ".daemon"
+}
+
+func openLock(name string) (*os.File, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating state dir: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, name+".lock"), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening session lock: %w", err)
+	}
+	return f, nil
+}
@@ -0,0 +1,230 @@
+// Package state persists one manifest per swarm session so every subcommand
+// works from the same record instead of re-deriving it from tmux and disk.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cpoulin/claude-swarm/internal/git"
+)
+
+// Worker is one agent of a swarm: its CLI, pane and worktree. PastBranches
+// are the branches it left for queued tasks; cleanup handles them with Branch.
+type Worker struct {
+	Index        int               `json:"index"`
+	CLI          string            `json:"cli"`
+	Model        string            `json:"model,omitempty"`
+	Flags        string            `json:"flags,omitempty"`
+	Env          map[string]string `json:"env,omitempty"`
+	Dir          string            `json:"dir,omitempty"`
+	Label        string            `json:"label,omitempty"`
+	Prompt       string            `json:"prompt,omitempty"`
+	PaneID       string            `json:"pane_id,omitempty"`
+	Worktree     string            `json:"worktree"`
+	Branch       string            `json:"branch"`
+	BaseBranch   string            `json:"base_branch"`
+	BaseCommit   string            `json:"base_commit"`
+	PastBranches []string          `json:"past_branches,omitempty"`
+	Shipped      bool              `json:"shipped,omitempty"`
+	PRURL        string            `json:"pr_url,omitempty"`
+}
+
+// Manifest is the persisted record of a swarm session.
+type Manifest struct {
+	Session   string    `json:"session"`
+	RepoRoot  string    `json:"repo_root"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Workers   []Worker  `json:"workers"`
+}
+
+// Dir returns the directory holding all manifests: <git-common-dir>/claude-swarm.
+func Dir() (string, error) {
+	common, err := git.CommonDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(common, "claude-swarm"), nil
+}
+
+// Path returns the manifest file path for session.
+func Path(session string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, session+".json"), nil
+}
+
+// Load reads the manifest for session. The error wraps os.ErrNotExist when
+// no swarm has been recorded under that name.
+func Load(session string) (*Manifest, error) {
+	path, err := Path(session)
+	if err != nil {
+		return nil, err
+	}
+	return loadFile(path)
+}
+
+func loadFile(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing manifest %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// Save atomically writes the manifest, creating the state directory if needed.
+func Save(m *Manifest) error {
+	path, err := Path(m.Session)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating state dir: %w", err)
+	}
+	m.UpdatedAt = time.Now().UTC()
+	sort.Slice(m.Workers, func(i, j int) bool { return m.Workers[i].Index < m.Workers[j].Index })
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Remove deletes the manifest for session; a missing manifest is not an error.
+func Remove(session string) error {
+	path, err := Path(session)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List returns every manifest recorded in this repository.
+func List() ([]*Manifest, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	manifests := make([]*Manifest, 0, len(paths))
+	for _, path := range paths {
+		m, err := loadFile(path)
+		if err != nil {
+			continue
+		}
+		manifests = append(manifests, m)
+	}
+	return manifests, nil
+}
+
+// FindWorktree returns the manifest and worker owning the worktree that
+// contains dir, searching every session of the repository.
+func FindWorktree(dir string) (*Manifest, *Worker, error) {
+	manifests, err := List()
+	if err != nil {
+		return nil, nil, err
+	}
+	dir = filepath.Clean(dir)
+	for _, m := range manifests {
+		for i := range m.Workers {
+			wt := filepath.Clean(m.Workers[i].Worktree)
+			if dir == wt || strings.HasPrefix(dir, wt+string(filepath.Separator)) {
+				return m, &m.Workers[i], nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("%s is not a recorded swarm worktree: %w", dir, os.ErrNotExist)
+}
+
+// Worker returns the worker with the given index, or nil.
+func (m *Manifest) Worker(index int) *Worker {
+	for i := range m.Workers {
+		if m.Workers[i].Index == index {
+			return &m.Workers[i]
+		}
+	}
+	return nil
+}
+
+// FreeIndices returns the n smallest worker indices not in use, so indices
+// left free by removed workers are filled before new ones are appended.
+func (m *Manifest) FreeIndices(n int) []int {
+	used := make(map[int]bool, len(m.Workers))
+	for _, w := range m.Workers {
+		used[w.Index] = true
+	}
+	free := make([]int, 0, n)
+	for i := 1; len(free) < n; i++ {
+		if !used[i] {
+			free = append(free, i)
+		}
+	}
+	return free
+}
+
+// RemoveWorker drops the worker with the given index from the manifest.
+func (m *Manifest) RemoveWorker(index int) {
+	kept := m.Workers[:0]
+	for _, w := range m.Workers {
+		if w.Index != index {
+			kept = append(kept, w)
+		}
+	}
+	m.Workers = kept
+}
+
+// SwitchBranch moves the worker to branch, recording the one it leaves in
+// PastBranches.
+func (w *Worker) SwitchBranch(branch, baseCommit string) {
+	if w.Branch != "" && w.Branch != branch && !slices.Contains(w.PastBranches, w.Branch) {
+		w.PastBranches = append(w.PastBranches, w.Branch)
+	}
+	w.PastBranches = slices.DeleteFunc(w.PastBranches, func(b string) bool { return b == branch })
+	w.Branch, w.BaseCommit = branch, baseCommit
+}
+
+// Branches returns Branch followed by PastBranches.
+func (w Worker) Branches() []string {
+	if w.Branch == "" {
+		return w.PastBranches
+	}
+	return append([]string{w.Branch}, w.PastBranches...)
+}
+
+// StartDir returns the directory the worker's CLI runs in: its worktree, or
+// the configured sub-directory of it.
+func (w Worker) StartDir() string {
+	if w.Dir == "" {
+		return w.Worktree
+	}
+	return filepath.Join(w.Worktree, w.Dir)
+}
+
+// Spec returns the worker's CLI in "cli" or "cli:model" form.
+func (w Worker) Spec() string {
+	if w.Model == "" {
+		return w.CLI
+	}
+	return w.CLI + ":" + w.Model
+}
@@ -0,0 +1,30 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFreeIndices(t *testing.T) {
+	m := &Manifest{Workers: []Worker{{Index: 1}, {Index: 3}, {Index: 4}, {Index: 7}}}
+	if got, want := m.FreeIndices(4), []int{2, 5, 6, 8}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FreeIndices(4) = %v, want %v", got, want)
+	}
+	if got := (&Manifest{}).FreeIndices(2); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("FreeIndices on empty manifest = %v, want [1 2]", got)
+	}
+}
+
+func TestSwitchBranch(t *testing.T) {
+	w := Worker{Branch: "swarm/s/main/worker-1"}
+	w.SwitchBranch("swarm/s/main/task-1", "a")
+	w.SwitchBranch("swarm/s/main/task-2", "b")
+	w.SwitchBranch("swarm/s/main/task-1", "c")
+	if w.Branch != "swarm/s/main/task-1" || w.BaseCommit != "c" {
+		t.Errorf("Branch, BaseCommit = %s, %s; want swarm/s/main/task-1, c", w.Branch, w.BaseCommit)
+	}
+	want := []string{"swarm/s/main/task-1", "swarm/s/main/worker-1", "swarm/s/main/task-2"}
+	if got := w.Branches(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Branches() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,57 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// PidPath returns the pidfile of the background monitor daemon for session.
+func PidPath(session string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, session+".pid"), nil
+}
+
+// WritePid records the current process as the daemon of session.
+func WritePid(session string) error {
+	path, err := PidPath(session)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating state dir: %w", err)
+	}
+	return os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644)
+}
+
+// DaemonPid returns the pid of the running daemon of session. ok is false if
+// there is no pidfile or the recorded process is gone; a stale pidfile is removed.
+func DaemonPid(session string) (pid int, ok bool) {
+	path, err := PidPath(session)
+	if err != nil {
+		return 0, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	pid, err = strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 || syscall.Kill(pid, 0) != nil {
+		_ = os.Remove(path)
+		return 0, false
+	}
+	return pid, true
+}
+
+// RemovePid deletes the daemon pidfile of session.
+func RemovePid(session string) {
+	if path, err := PidPath(session); err == nil {
+		_ = os.Remove(path)
+	}
+}
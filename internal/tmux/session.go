@@ -3,7 +3,9 @@ package tmux
 import (
 	"fmt"
 	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 )
 
 func run(args ...string) error {
@@ -82,20 +84,52 @@ func CapturePane(target string) (string, error) {
 	return string(out), nil
 }
 
+// CapturePaneStyled is CapturePane with the text attributes and colours kept
+// as escape sequences (capture-pane -e). It also returns the pane's
+// history_size, read in the same tmux command, so line r of the content is
+// line history+r of the pane's scrollback.
+func CapturePaneStyled(target string) (content string, history int, err error) {
+	out, err := exec.Command("tmux", "display-message", "-p", "-t", target, "#{history_size}",
+		";", "capture-pane", "-t", target, "-p", "-e").Output()
+	if err != nil {
+		return "", 0, fmt.Errorf("tmux capture-pane -t %s: %w", target, err)
+	}
+	size, content, _ := strings.Cut(string(out), "\n")
+	history, err = strconv.Atoi(size)
+	if err != nil {
+		return "", 0, fmt.Errorf("tmux capture-pane -t %s: history_size %q", target, size)
+	}
+	return content, history, nil
+}
+
+// ClearHistory drops the scrollback of a pane.
+func ClearHistory(target string) error {
+	return run("clear-history", "-t", target)
+}
+
 // SetOption sets a tmux option on a session.
 func SetOption(session, key, value string) error {
 	return run("set-option", "-t", session, key, value)
 }
 
-// BindKey binds a key in a session's key table.
-// flags may be e.g. "-n" (no prefix) or "" (use prefix).
-func BindKey(session, flags, key, command string) error {
-	args := []string{"bind-key"}
-	if flags != "" {
-		args = append(args, flags)
+// SwarmOption marks a session as a swarm. No-prefix bindings only act in
+// sessions that have it.
+const SwarmOption = "@swarm"
+
+// BindKey binds a key. flags may be "-n" (no prefix) or "" (use prefix). Key
+// tables are global, so command should find its targets from the client's
+// session (#{session_name}, expanded by run-shell). A no-prefix key runs
+// command only in a session with SwarmOption set and reaches the pane as
+// usual everywhere else; the root table's other bindings, mouse included,
+// are left alone.
+func BindKey(flags, key, command string) error {
+	switch flags {
+	case "-n":
+		return run("bind-key", "-n", key, "if-shell", "-F", "#{"+SwarmOption+"}", command, "send-keys "+key)
+	case "":
+		return run("bind-key", key, command)
 	}
-	args = append(args, key, command)
-	return run(args...)
+	return run("bind-key", flags, key, command)
 }
 
 // SelectWindow selects (focuses) a window by target.
@@ -170,3 +204,134 @@ func MaxWindowIndex(session string) (int, error) {
 	}
 	return max, nil
 }
+
+// NewWindowGetPaneID creates a detached named window after target and returns its first pane's %N ID.
+func NewWindowGetPaneID(target, cwd, name string) (string, error) {
+	args := []string{"new-window", "-d", "-a", "-t", target, "-c", cwd, "-P", "-F", "#{pane_id}"}
+	if name != "" {
+		args = append(args, "-n", name)
+	}
+	out, err := exec.Command("tmux", args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("tmux new-window -t %s: %w", target, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ListWindowNames returns the window names of a session in index order.
+func ListWindowNames(session string) ([]string, error) {
+	out, err := exec.Command("tmux", "list-windows", "-t", session, "-F", "#{window_name}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("tmux list-windows -t %s: %w", session, err)
+	}
+	return splitLines(string(out)), nil
+}
+
+// ListPanes returns the %N pane IDs of a window in pane index order.
+func ListPanes(target string) ([]string, error) {
+	out, err := exec.Command("tmux", "list-panes", "-t", target, "-F", "#{pane_id}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("tmux list-panes -t %s: %w", target, err)
+	}
+	return splitLines(string(out)), nil
+}
+
+// SelectLayout applies a named layout (tiled, main-vertical, …) to a window.
+func SelectLayout(target, layout string) error {
+	return run("select-layout", "-t", target, layout)
+}
+
+func splitLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// PaneInfo describes one pane of a session as reported by list-panes.
+type PaneInfo struct {
+	ID      string
+	Window  string
+	Title   string
+	Path    string
+	Command string
+}
+
+// ListSessionPanes returns every pane of every window in session.
+func ListSessionPanes(session string) ([]PaneInfo, error) {
+	out, err := exec.Command("tmux", "list-panes", "-s", "-t", session, "-F",
+		"#{pane_id}\t#{window_name}\t#{pane_title}\t#{pane_current_path}\t#{pane_current_command}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("tmux list-panes -s -t %s: %w", session, err)
+	}
+	var panes []PaneInfo
+	for _, line := range splitLines(string(out)) {
+		f := strings.SplitN(line, "\t", 5)
+		if len(f) < 5 {
+			continue
+		}
+		panes = append(panes, PaneInfo{ID: f[0], Window: f[1], Title: f[2], Path: f[3], Command: f[4]})
+	}
+	return panes, nil
+}
+
+// PasteText types text into a pane as a single bracketed paste, so multi-line
+// text reaches the program as one message instead of line by line. It does
+// not press Enter.
+func PasteText(target, text string) error {
+	buffer := "claude-swarm-" + strings.TrimPrefix(target, "%")
+	if err := run("set-buffer", "-b", buffer, "--", text); err != nil {
+		return err
+	}
+	return run("paste-buffer", "-p", "-d", "-b", buffer, "-t", target)
+}
+
+// SendText pastes text into a pane and presses Enter, submitting it to the
+// program as one message. Unlike SendKeys it keeps multi-line text together
+// and never interprets words as key names.
+func SendText(target, text string) error {
+	if err := PasteText(target, text); err != nil {
+		return err
+	}
+	// Give the program a moment to take the paste in before submitting it.
+	time.Sleep(300 * time.Millisecond)
+	return SendRaw(target, "Enter")
+}
+
+// SendRaw sends key names (e.g. "Enter", "Escape", "C-c") to a pane without an implicit Enter.
+func SendRaw(target string, keys ...string) error {
+	return run(append([]string{"send-keys", "-t", target}, keys...)...)
+}
+
+// DisplayFormat expands a tmux format (e.g. "#{pane_current_command}") for target.
+func DisplayFormat(target, format string) (string, error) {
+	out, err := exec.Command("tmux", "display-message", "-p", "-t", target, format).Output()
+	if err != nil {
+		return "", fmt.Errorf("tmux display-message -t %s: %w", target, err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+// SetPaneOption sets a pane option, such as a user option "@name".
+func SetPaneOption(target, key, value string) error {
+	return run("set-option", "-p", "-t", target, key, value)
+}
+
+// UnsetPaneOption removes a pane option.
+func UnsetPaneOption(target, key string) error {
+	return run("set-option", "-p", "-u", "-t", target, key)
+}
+
+// KillPane closes a pane and the process running in it.
+func KillPane(target string) error {
+	return run("kill-pane", "-t", target)
+}
+
+// RespawnPane kills whatever runs in a pane and starts a fresh shell in cwd,
+// keeping the pane ID, title and position.
+func RespawnPane(target, cwd string) error {
+	return run("respawn-pane", "-k", "-t", target, "-c", cwd)
+}
@@ -7,9 +7,6 @@ import (
 )
 
 var (
-	errorRe = regexp.MustCompile(
-		`(?i)(exceeded your usage limit|exceeded your current quota|insufficient.{0,10}quota|usage limits.{0,60}try again after|rate limit.{0,60}retry after)`,
-	)
 	utcTimeRe = regexp.MustCompile(`(?i)after (\d+):(\d+) UTC`)
 	hoursRe   = regexp.MustCompile(`(?i)in (\d+) hours?`)
 	minsRe    = regexp.MustCompile(`(?i)(\d+) minutes?`)
@@ -19,11 +16,13 @@ var (
 
 // HasError reports whether text contains an API usage-limit message.
 func HasError(text string) bool {
-	return errorRe.MatchString(text)
+	return defaultParser.HasError("", text)
 }
 
 // ExtractWaitSecs parses the wait duration from error text and returns seconds.
 // Priority: UTC timestamp → "in X hours Y minutes" → 3600 fallback.
+//
+// Deprecated: use Parse.
 func ExtractWaitSecs(text string) int {
 	// Primary: "after HH:MM UTC" — compute delta from now to that wall-clock time (UTC)
 	if m := utcTimeRe.FindStringSubmatch(text); len(m) == 3 {
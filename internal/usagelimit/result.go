@@ -0,0 +1,396 @@
+package usagelimit
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // named zones such as America/New_York on any host
+)
+
+// Kind is what sort of limit was hit.
+type Kind string
+
+const (
+	Session Kind = "session" // rolling usage window (e.g. Claude's 5-hour limit)
+	Weekly  Kind = "weekly"  // weekly usage cap
+	Rate    Kind = "rate"    // too many requests in a short time
+	Quota   Kind = "quota"   // daily or billing quota
+)
+
+// Confidence is how sure Parse is about ResetAt.
+type Confidence int
+
+const (
+	Guessed Confidence = iota // no reset time found; ResetAt is one hour away
+	Assumed                   // reset time found without a zone; the local zone was assumed
+	Exact                     // reset time with a zone, an epoch, or a relative wait
+)
+
+func (c Confidence) String() string {
+	switch c {
+	case Exact:
+		return "exact"
+	case Assumed:
+		return "assumed zone"
+	}
+	return "guessed"
+}
+
+// Result describes a usage-limit message.
+type Result struct {
+	Found      bool
+	Rule       string // name of the rule that matched
+	Provider   string // claude, gemini or codex; empty if the message does not say
+	Kind       Kind
+	ResetAt    time.Time
+	Wait       time.Duration // from the time of parsing to ResetAt, never negative
+	Confidence Confidence
+	Matched    string // the limit message, lines joined
+	Line       string // the line the message starts on, which identifies it on screen
+	Row        int    // index of that line in the parsed text
+}
+
+// fallbackWait is assumed when a limit message gives no reset time and its
+// rule no default wait. Rate limits are per minute and clear sooner.
+const (
+	fallbackWait     = time.Hour
+	rateFallbackWait = time.Minute
+)
+
+const (
+	durationExpr = `(?:\d+(?:\.\d+)?\s*(?:days?|hours?|hrs?|minutes?|mins?|seconds?|secs?|d|h|m|s)[\s,]*(?:and\s+)?)+`
+	zoneExpr     = `[a-z]+/[a-z_-]+(?:/[a-z_-]+)?|utc|gmt|[pcme][sd]t|cest|cet|bst`
+	clockExpr    = `(?:(?:mon|tue|wed|thu|fri|sat|sun)[a-z]*\.?,?\s+)?` +
+		`(?:(?:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?\s+\d{1,2}(?:st|nd|rd|th)?(?:,?\s*\d{4})?,?\s+(?:at\s+)?)?` +
+		`\d{1,2}(?::\d{2})?\s*(?:[ap]\.?m\.?)?(?:\s*\(?(?:` + zoneExpr + `)\)?)?`
+)
+
+var (
+	epochRe    = regexp.MustCompile(`\|(\d{10})\b`)
+	relativeRe = regexp.MustCompile(`(?i)\b(?:try again|retry|resets?|available again)\s+(?:in|after)\s+(` + durationExpr + `)|retry_?delay"?\s*:\s*"?(` + durationExpr + `)`)
+	absoluteRe = regexp.MustCompile(`(?i)\b(?:resets?(?:\s+at)?|try again (?:at|after)|available (?:again )?at|after)\s+(?:on\s+)?(` + clockExpr + `)`)
+	isoRe      = regexp.MustCompile(`\b(\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}(?::\d{2})?(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?)`)
+	unitRe     = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*(days?|hours?|hrs?|minutes?|mins?|seconds?|secs?|d|h|m|s)`)
+	clockRe    = regexp.MustCompile(`(?i)^(?:(mon|tue|wed|thu|fri|sat|sun)[a-z]*\.?,?\s+)?` +
+		`(?:(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?\s+(\d{1,2})(?:st|nd|rd|th)?(?:,?\s*(\d{4}))?,?\s+(?:at\s+)?)?` +
+		`(\d{1,2})(?::(\d{2}))?\s*([ap])?\.?(?:m\.?)?(?:\s*\(?(` + zoneExpr + `)\)?)?$`)
+)
+
+// zoneAbbrevs maps the zone abbreviations CLIs print to locations.
+var zoneAbbrevs = map[string]string{
+	"utc": "UTC", "gmt": "UTC",
+	"pst": "America/Los_Angeles", "pdt": "America/Los_Angeles",
+	"mst": "America/Denver", "mdt": "America/Denver",
+	"cst": "America/Chicago", "cdt": "America/Chicago",
+	"est": "America/New_York", "edt": "America/New_York",
+	"cet": "Europe/Paris", "cest": "Europe/Paris",
+	"bst": "Europe/London",
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"may": time.May, "jun": time.June, "jul": time.July, "aug": time.August,
+	"sep": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+// Parse finds the most recent usage-limit message in text using the built-in
+// rules and works out when the limit resets. Times without a zone are read in
+// now's location.
+func Parse(text string, now time.Time) Result {
+	return defaultParser.Parse("", text, now)
+}
+
+// Parse is like the package Parse, with p's rules, and only the rules of
+// provider (and those for any provider) unless provider is empty.
+func (p *Parser) Parse(provider, text string, now time.Time) Result {
+	// The match on the lowest line wins; on one line, the first rule does.
+	var best *compiledRule
+	var loc []int
+	line := -1
+	for i, r := range p.rules {
+		if !r.applies(provider) {
+			continue
+		}
+		all := r.match.FindAllStringSubmatchIndex(text, -1)
+		if len(all) == 0 {
+			continue
+		}
+		m := all[len(all)-1]
+		if l := strings.LastIndexByte(text[:m[0]], '\n'); l > line || loc == nil {
+			best, loc, line = &p.rules[i], m, l
+		}
+	}
+	if best == nil {
+		return Result{}
+	}
+
+	res := Result{Found: true, Rule: best.Name, Provider: best.Provider, Kind: best.Kind, Matched: message(text, loc[0])}
+	start := line + 1
+	res.Row = strings.Count(text[:start], "\n")
+	res.Line, _, _ = strings.Cut(text[start:], "\n")
+	res.Line = strings.TrimSpace(res.Line)
+	if res.Provider == "" {
+		res.Provider = inferProvider(res.Matched)
+	}
+	if res.Provider == "" {
+		res.Provider = provider
+	}
+	if res.Kind == "" {
+		res.Kind = inferKind(res.Matched)
+	}
+
+	reset, conf, ok := groupReset(best.match, text, loc, now)
+	if !ok && best.reset != nil {
+		if rl := best.reset.FindStringSubmatchIndex(res.Matched); rl != nil {
+			reset, conf, ok = groupReset(best.reset, res.Matched, rl, now)
+		}
+	}
+	if !ok {
+		reset, conf, ok = findReset(res.Matched, now)
+	}
+	if !ok {
+		wait := best.DefaultWait
+		if wait <= 0 && res.Kind == Rate {
+			wait = rateFallbackWait
+		} else if wait <= 0 {
+			wait = fallbackWait
+		}
+		reset, conf = now.Add(wait), Guessed
+	}
+	res.ResetAt, res.Confidence = reset, conf
+	res.Wait = max(reset.Sub(now), 0)
+	return res
+}
+
+// message returns the line holding offset i and the two after it, which is
+// where CLIs print the reset time (or where a long line wrapped to).
+func message(text string, i int) string {
+	start := strings.LastIndexByte(text[:i], '\n') + 1
+	lines := strings.SplitN(text[start:], "\n", 4)
+	if len(lines) > 3 {
+		lines = lines[:3]
+	}
+	for j := range lines {
+		lines[j] = strings.TrimSpace(lines[j])
+	}
+	return strings.TrimSpace(strings.Join(lines, " "))
+}
+
+// groupReset reads the reset from the named groups of re matched at loc:
+// "epoch", "in", "hours"/"minutes"/"seconds", or "at" with an optional "zone".
+func groupReset(re *regexp.Regexp, text string, loc []int, now time.Time) (time.Time, Confidence, bool) {
+	var at, zone string
+	var secs float64
+	relative := false
+	for i, name := range re.SubexpNames() {
+		if name == "" || loc[2*i] < 0 {
+			continue
+		}
+		v := strings.TrimSpace(text[loc[2*i]:loc[2*i+1]])
+		switch name {
+		case "epoch":
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return time.Unix(n, 0), Exact, true
+			}
+		case "in":
+			if d, ok := parseDuration(v); ok {
+				return now.Add(d), Exact, true
+			}
+		case "hours", "minutes", "seconds":
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			secs += n * map[string]float64{"hours": 3600, "minutes": 60, "seconds": 1}[name]
+			relative = true
+		case "at":
+			at = v
+		case "zone":
+			zone = v
+		}
+	}
+	if relative {
+		return now.Add(time.Duration(math.Ceil(secs)) * time.Second), Exact, true
+	}
+	if at != "" {
+		if zone != "" {
+			at += " (" + zone + ")"
+		}
+		return parseClock(at, now)
+	}
+	return time.Time{}, 0, false
+}
+
+// findReset looks for the reset time in a limit message.
+func findReset(msg string, now time.Time) (time.Time, Confidence, bool) {
+	if m := epochRe.FindStringSubmatch(msg); m != nil {
+		n, _ := strconv.ParseInt(m[1], 10, 64)
+		return time.Unix(n, 0), Exact, true
+	}
+	if m := relativeRe.FindStringSubmatch(msg); m != nil {
+		if d, ok := parseDuration(m[1] + m[2]); ok {
+			return now.Add(d), Exact, true
+		}
+	}
+	if m := isoRe.FindStringSubmatch(msg); m != nil {
+		if t, conf, ok := parseISO(m[1], now); ok {
+			return t, conf, true
+		}
+	}
+	for _, m := range absoluteRe.FindAllStringSubmatch(msg, -1) {
+		if t, conf, ok := parseClock(m[1], now); ok {
+			return t, conf, true
+		}
+	}
+	return time.Time{}, 0, false
+}
+
+// parseDuration reads durations like "2 days 3 hours", "4h 32m", "1m30s" or
+// "45.39s", rounding up to the second.
+func parseDuration(s string) (time.Duration, bool) {
+	var total float64
+	found := false
+	for _, m := range unitRe.FindAllStringSubmatch(s, -1) {
+		n, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			continue
+		}
+		switch strings.ToLower(m[2])[0] {
+		case 'd':
+			n *= 86400
+		case 'h':
+			n *= 3600
+		case 'm':
+			n *= 60
+		}
+		total += n
+		found = true
+	}
+	return time.Duration(math.Ceil(total)) * time.Second, found
+}
+
+func parseISO(s string, now time.Time) (time.Time, Confidence, bool) {
+	s = strings.Replace(s, " ", "T", 1)
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04Z07:00", "2006-01-02T15:04:05Z0700"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, Exact, true
+		}
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, Assumed, true
+		}
+	}
+	return time.Time{}, 0, false
+}
+
+// parseClock reads a reset such as "5pm (America/New_York)", "Oct 18, 3am",
+// "Mon 9am" or "15:00 UTC" and returns its next occurrence after now. A bare
+// number is not a time: it needs minutes or am/pm.
+func parseClock(s string, now time.Time) (time.Time, Confidence, bool) {
+	m := clockRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil || (m[6] == "" && m[7] == "") {
+		return time.Time{}, 0, false
+	}
+	wday, mon, day, year, hour, minute, ampm, zone := m[1], m[2], m[3], m[4], m[5], m[6], m[7], m[8]
+
+	h, _ := strconv.Atoi(hour)
+	mi, _ := strconv.Atoi(minute)
+	switch strings.ToLower(ampm) {
+	case "a", "p":
+		if h < 1 || h > 12 {
+			return time.Time{}, 0, false
+		}
+		h %= 12
+		if strings.EqualFold(ampm, "p") {
+			h += 12
+		}
+	}
+	if h > 23 || mi > 59 {
+		return time.Time{}, 0, false
+	}
+
+	loc, conf := now.Location(), Assumed
+	if zone != "" {
+		name := zone
+		if abbrev, ok := zoneAbbrevs[strings.ToLower(zone)]; ok {
+			name = abbrev
+		}
+		l, err := time.LoadLocation(name)
+		if err != nil {
+			return time.Time{}, 0, false
+		}
+		loc, conf = l, Exact
+	}
+	ref := now.In(loc)
+
+	switch {
+	case mon != "":
+		d, _ := strconv.Atoi(day)
+		y := ref.Year()
+		if year != "" {
+			y, _ = strconv.Atoi(year)
+		}
+		t := time.Date(y, months[strings.ToLower(mon)[:3]], d, h, mi, 0, 0, loc)
+		if year == "" && t.Before(ref.Add(-24*time.Hour)) {
+			t = t.AddDate(1, 0, 0) // "Jan 2" seen in December
+		}
+		return t, conf, true
+	case wday != "":
+		t := time.Date(ref.Year(), ref.Month(), ref.Day(), h, mi, 0, 0, loc)
+		ahead := (int(weekdays[strings.ToLower(wday)[:3]]) - int(ref.Weekday()) + 7) % 7
+		t = t.AddDate(0, 0, ahead)
+		if !t.After(ref) {
+			t = t.AddDate(0, 0, 7)
+		}
+		return t, conf, true
+	}
+	t := time.Date(ref.Year(), ref.Month(), ref.Day(), h, mi, 0, 0, loc)
+	if !t.After(ref) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, conf, true
+}
+
+var (
+	weeklyRe = regexp.MustCompile(`(?i)\bweek(ly)?\b`)
+	quotaRe  = regexp.MustCompile(`(?i)\b(quota|credits?|billing|per day|daily)\b`)
+	rateRe   = regexp.MustCompile(`(?i)(rate.?limit|\b429\b|too many requests|per minute)`)
+)
+
+func inferKind(msg string) Kind {
+	switch {
+	case weeklyRe.MatchString(msg):
+		return Weekly
+	case quotaRe.MatchString(msg):
+		return Quota
+	case rateRe.MatchString(msg):
+		return Rate
+	}
+	return Session
+}
+
+var providerHints = []struct {
+	provider string
+	re       *regexp.Regexp
+}{
+	{"claude", regexp.MustCompile(`(?i)\b(claude|anthropic|opus|sonnet)\b`)},
+	{"gemini", regexp.MustCompile(`(?i)\b(gemini|google)\b`)},
+	{"codex", regexp.MustCompile(`(?i)\b(codex|openai|chatgpt|gpt-)`)},
+}
+
+func inferProvider(msg string) string {
+	for _, h := range providerHints {
+		if h.re.MatchString(msg) {
+			return h.provider
+		}
+	}
+	return ""
+}
@@ -0,0 +1,134 @@
+package usagelimit
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func TestParse(t *testing.T) {
+	// Friday 16 October 2026, 14:07 UTC.
+	now := time.Date(2026, 10, 16, 14, 7, 0, 0, time.UTC)
+	ny := mustLoad(t, "America/New_York")
+	la := mustLoad(t, "America/Los_Angeles")
+	paris := mustLoad(t, "Europe/Paris")
+
+	tests := []struct {
+		name     string
+		text     string
+		provider string
+		kind     Kind
+		reset    time.Time
+		conf     Confidence
+	}{
+		{"claude session 12h clock with zone", "⎿  5-hour limit reached ∙ resets 5pm (America/New_York)\n     /upgrade to increase your usage limit.",
+			"claude", Session, time.Date(2026, 10, 16, 17, 0, 0, 0, ny), Exact},
+		{"claude hit your limit", "● You've hit your limit · resets 5:30pm (Europe/Paris)",
+			"", Session, time.Date(2026, 10, 16, 17, 30, 0, 0, paris), Exact},
+		{"zone clock already passed", "● You've hit your limit · resets 3:30pm (Europe/Paris)",
+			"", Session, time.Date(2026, 10, 17, 15, 30, 0, 0, paris), Exact},
+		{"claude weekly with date", "Weekly limit reached ∙ resets Oct 18, 3am (America/Los_Angeles)",
+			"claude", Weekly, time.Date(2026, 10, 18, 3, 0, 0, 0, la), Exact},
+		{"claude opus weekly with weekday", "Opus weekly limit reached ∙ resets Mon 9am",
+			"claude", Weekly, time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC), Assumed},
+		{"claude reset already passed today", "Session limit reached ∙ resets 1am",
+			"claude", Session, time.Date(2026, 10, 17, 1, 0, 0, 0, time.UTC), Assumed},
+		{"claude older wording on next line", "Claude usage limit reached.\nYour limit will reset at 7pm (America/New_York).",
+			"claude", Session, time.Date(2026, 10, 16, 19, 0, 0, 0, ny), Exact},
+		{"claude epoch", "Claude AI usage limit reached|1792177200",
+			"claude", Session, time.Unix(1792177200, 0), Exact},
+		{"claude api rate limit", `API Error: 429 {"type":"error","error":{"type":"rate_limit_error","message":"Number of request tokens has exceeded your per-minute rate limit"}}`,
+			"claude", Rate, now.Add(rateFallbackWait), Guessed},
+		{"legacy utc", "API usage limits — try again after 15:00 UTC",
+			"", Session, time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC), Exact},
+		{"codex relative days", "■ You've hit your usage limit. Upgrade to Pro (https://openai.com/chatgpt/pricing) or try again in 2 days 3 hours 5 minutes.",
+			"codex", Session, now.Add(51*time.Hour + 5*time.Minute), Exact},
+		{"codex compact relative", "■ You've hit your usage limit. Try again in 4h 32m.",
+			"", Session, now.Add(4*time.Hour + 32*time.Minute), Exact},
+		{"codex absolute with year", "You've hit your usage limit. Upgrade to Plus to continue using Codex, or try again at Oct 18th, 2026 9:15 PM.",
+			"codex", Session, time.Date(2026, 10, 18, 21, 15, 0, 0, time.UTC), Assumed},
+		{"openai rate limit seconds", "stream error: Rate limit reached for gpt-5 in organization org-x on tokens per min (TPM): Limit 30000, Used 29000. Please try again in 1.2s.",
+			"codex", Rate, now.Add(2 * time.Second), Exact},
+		{"openai quota", "You exceeded your current quota, please check your plan and billing details.",
+			"codex", Quota, now.Add(fallbackWait), Guessed},
+		{"gemini daily quota", "✕ [API Error: Quota exceeded for quota metric 'Gemini 2.5 Pro Requests' and limit 'Gemini 2.5 Pro Requests per day per user per tier']",
+			"gemini", Quota, now.Add(fallbackWait), Guessed},
+		{"gemini retry delay", `{"error":{"code":429,"status":"RESOURCE_EXHAUSTED","details":[{"@type":"type.googleapis.com/google.rpc.RetryInfo","retryDelay":"45s"}]}}`,
+			"gemini", Rate, now.Add(45 * time.Second), Exact},
+		{"gemini please retry", "RESOURCE_EXHAUSTED: You exceeded your current quota. Please retry in 45.39s.",
+			"gemini", Rate, now.Add(46 * time.Second), Exact},
+		{"iso timestamp", "You've hit your usage limit. Resets at 2026-10-16T18:00:00Z.",
+			"", Session, time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC), Exact},
+		{"abbreviated zone", "You've hit your limit · resets 11am PST",
+			"", Session, time.Date(2026, 10, 16, 11, 0, 0, 0, la), Exact},
+		{"legacy rate limit", "Rate limit exceeded, retry after 1 hour",
+			"", Rate, now.Add(time.Hour), Exact},
+		{"no reset given", "You have exceeded your usage limit for today.",
+			"", Session, now.Add(fallbackWait), Guessed},
+		{"latest message wins", "Claude AI usage limit reached|1792160000\n…\n5-hour limit reached ∙ resets 6pm (America/New_York)",
+			"claude", Session, time.Date(2026, 10, 16, 18, 0, 0, 0, ny), Exact},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Parse(tt.text, now)
+			if !r.Found {
+				t.Fatalf("Parse(%q) found no limit", tt.text)
+			}
+			if r.Provider != tt.provider || r.Kind != tt.kind || r.Confidence != tt.conf {
+				t.Errorf("provider, kind, confidence = %q, %s, %s; want %q, %s, %s",
+					r.Provider, r.Kind, r.Confidence, tt.provider, tt.kind, tt.conf)
+			}
+			if !r.ResetAt.Equal(tt.reset) {
+				t.Errorf("ResetAt = %s, want %s", r.ResetAt, tt.reset.UTC())
+			}
+			if want := max(tt.reset.Sub(now), 0); r.Wait != want {
+				t.Errorf("Wait = %s, want %s", r.Wait, want)
+			}
+			if r.Matched == "" {
+				t.Error("Matched is empty")
+			}
+			if r.Line == "" || !strings.HasPrefix(r.Matched, r.Line) {
+				t.Errorf("Line = %q, want the first line of %q", r.Line, r.Matched)
+			}
+		})
+	}
+}
+
+func TestParseNoLimit(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"Everything is fine, carry on.",
+		"● The session resets at 5pm every day, per the README.",
+		"Fixed the rate limiter; requests after 15:00 are throttled.",
+	} {
+		if r := Parse(text, time.Now()); r.Found {
+			t.Errorf("Parse(%q) found a %s limit in %q", text, r.Kind, r.Matched)
+		}
+	}
+}
+
+func TestParseClockRejectsBareNumbers(t *testing.T) {
+	now := time.Date(2026, 10, 16, 14, 7, 0, 0, time.UTC)
+	for _, s := range []string{"3", "25:00", "13pm", "0am"} {
+		if _, _, ok := parseClock(s, now); ok {
+			t.Errorf("parseClock(%q) accepted a non-time", s)
+		}
+	}
+}
+
+func TestZoneAbbrevsLoad(t *testing.T) {
+	for abbrev, name := range zoneAbbrevs {
+		if _, err := time.LoadLocation(name); err != nil {
+			t.Errorf("%s → %s: %v", abbrev, name, err)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package usagelimit
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Rule recognises one usage-limit message.
+//
+// Match finds the message. Reset, if set, is matched against the message
+// (the matching line and the two after it) to read the reset time from
+// named groups: "epoch" (Unix seconds), "in" (a duration like "4h 32m"),
+// "hours", "minutes" and "seconds" (numbers, summed), or "at" (a clock time
+// such as "5pm", "Oct 18, 3am" or "15:00 UTC") with an optional "zone".
+// Match may use the same groups. Without them, or if they do not match, the
+// reset is looked for in the message with the built-in formats, and failing
+// that DefaultWait is assumed, or one minute for rate limits and one hour
+// for the others.
+//
+// Provider limits the rule to workers of that CLI; empty means any. Kind may
+// be empty to infer it from the message.
+type Rule struct {
+	Name        string
+	Provider    string
+	Kind        Kind
+	Match       string
+	Reset       string
+	DefaultWait time.Duration
+}
+
+// Builtin are the messages of the supported CLIs and their APIs.
+var Builtin = []Rule{
+	{Name: "claude-epoch", Provider: "claude", Kind: Session, Match: `Claude AI usage limit reached\|(?P<epoch>\d{10})`},
+	{Name: "claude-weekly", Provider: "claude", Kind: Weekly, Match: `(?i)\b(?:opus |sonnet )?weekly limit reached`},
+	{Name: "claude-session", Provider: "claude", Kind: Session, Match: `(?i)\b(?:5-hour|session) limit reached`},
+	{Name: "claude-usage", Provider: "claude", Kind: Session, Match: `(?i)\bclaude (?:ai )?usage limit reached`},
+	{Name: "claude-reset-at", Provider: "claude", Kind: Session, Match: `(?i)\byour limit will reset at`},
+	{Name: "claude-api-rate", Provider: "claude", Kind: Rate, Match: `(?i)"type"\s*:\s*"rate_limit_error"`},
+	{Name: "hit-your-limit", Match: `(?i)\byou've hit your (?:usage )?limit\b`},
+	{Name: "gemini-quota-metric", Provider: "gemini", Kind: Quota, Match: `(?i)\bquota exceeded for quota metric`},
+	{Name: "gemini-daily-quota", Provider: "gemini", Kind: Quota, Match: `(?i)\byou have reached your daily\b.{0,40}\bquota`},
+	{Name: "gemini-exhausted", Provider: "gemini", Kind: Rate, Match: `(?i)\bRESOURCE_EXHAUSTED\b`},
+	{Name: "openai-rate", Provider: "codex", Kind: Rate, Match: `(?i)\brate limit reached for\b`},
+	{Name: "openai-quota", Provider: "codex", Kind: Quota, Match: `(?i)\b(?:exceeded your current quota|insufficient.{0,10}quota)`},
+	{Name: "exceeded-usage", Match: `(?i)\bexceeded your usage limit`},
+	{Name: "usage-limits", Match: `(?i)\busage limits.{0,60}try again after`},
+	{Name: "rate-limit-retry", Kind: Rate, Match: `(?i)\brate limit.{0,60}retry after`},
+	{Name: "http-429", Kind: Rate, Match: `(?i)\b429 too many requests`},
+}
+
+// Parser recognises usage-limit messages with a set of rules.
+type Parser struct {
+	rules       []compiledRule
+	statusLines int
+}
+
+type compiledRule struct {
+	Rule
+	match, reset *regexp.Regexp
+}
+
+func (r compiledRule) applies(provider string) bool {
+	return provider == "" || r.Provider == "" || r.Provider == provider
+}
+
+var defaultParser = mustParser(nil)
+
+// Default returns a parser with only the built-in rules.
+func Default() *Parser {
+	return defaultParser
+}
+
+// NewParser returns a parser with the given rules followed by the built-in
+// ones. A rule named like a built-in rule replaces it; on the same screen
+// line, earlier rules win.
+func NewParser(rules []Rule) (*Parser, error) {
+	replaced := make(map[string]bool, len(rules))
+	p := &Parser{}
+	for i, r := range rules {
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		c, err := compile(r)
+		if err != nil {
+			return nil, err
+		}
+		replaced[r.Name] = true
+		p.rules = append(p.rules, c)
+	}
+	for _, r := range Builtin {
+		if replaced[r.Name] {
+			continue
+		}
+		c, err := compile(r)
+		if err != nil {
+			return nil, err
+		}
+		p.rules = append(p.rules, c)
+	}
+	return p, nil
+}
+
+func mustParser(rules []Rule) *Parser {
+	p, err := NewParser(rules)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func compile(r Rule) (compiledRule, error) {
+	switch r.Kind {
+	case "", Session, Weekly, Rate, Quota:
+	default:
+		return compiledRule{}, fmt.Errorf("rule %s: unknown kind %q — use session, weekly, rate or quota", r.Name, r.Kind)
+	}
+	if r.Match == "" {
+		return compiledRule{}, fmt.Errorf("rule %s: match is empty", r.Name)
+	}
+	c := compiledRule{Rule: r}
+	var err error
+	if c.match, err = regexp.Compile(r.Match); err != nil {
+		return compiledRule{}, fmt.Errorf("rule %s: match: %w", r.Name, err)
+	}
+	if r.Reset != "" {
+		if c.reset, err = regexp.Compile(r.Reset); err != nil {
+			return compiledRule{}, fmt.Errorf("rule %s: reset: %w", r.Name, err)
+		}
+	}
+	return c, nil
+}
+
+// HasError reports whether text contains a usage-limit message of provider
+// (any provider if empty).
+func (p *Parser) HasError(provider, text string) bool {
+	for _, r := range p.rules {
+		if r.applies(provider) && r.match.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
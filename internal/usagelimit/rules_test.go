@@ -0,0 +1,66 @@
+package usagelimit
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewParserCustomRules(t *testing.T) {
+	now := time.Date(2026, 10, 16, 14, 7, 0, 0, time.UTC)
+	p, err := NewParser([]Rule{
+		{Name: "cap", Provider: "claude", Match: `(?i)usage cap hit`, Reset: `back in (?P<hours>\d+)h(?: (?P<minutes>\d+)m)?`},
+		{Name: "cap-at", Provider: "claude", Kind: Weekly, Match: `(?i)weekly cap hit`, Reset: `(?i)back at (?P<at>\d+[ap]m) (?P<zone>\S+/\S+)`},
+		{Name: "silent", Provider: "gemini", Kind: Quota, Match: `(?i)out of juice`, DefaultWait: 3 * time.Hour},
+		{Name: "http-429", Kind: Rate, Match: `(?i)\b429\b`, DefaultWait: time.Minute},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ny, _ := time.LoadLocation("America/New_York")
+
+	tests := []struct {
+		name, provider, text, rule string
+		kind                       Kind
+		reset                      time.Time
+		conf                       Confidence
+	}{
+		{"hours and minutes groups", "claude", "Usage cap hit — back in 2h 30m", "cap", Session, now.Add(150 * time.Minute), Exact},
+		{"at and zone groups", "claude", "Weekly cap hit. Back at 5pm America/New_York", "cap-at", Weekly, time.Date(2026, 10, 16, 17, 0, 0, 0, ny), Exact},
+		{"default wait", "gemini", "Out of juice!", "silent", Quota, now.Add(3 * time.Hour), Guessed},
+		{"replaces a built-in", "codex", "HTTP 429 Too Many Requests", "http-429", Rate, now.Add(time.Minute), Guessed},
+		{"built-ins still apply", "claude", "5-hour limit reached ∙ resets 5pm (America/New_York)", "claude-session", Session, time.Date(2026, 10, 16, 17, 0, 0, 0, ny), Exact},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := p.Parse(tt.provider, tt.text, now)
+			if r.Rule != tt.rule || r.Kind != tt.kind || r.Confidence != tt.conf || !r.ResetAt.Equal(tt.reset) {
+				t.Errorf("Parse() = rule %q, %s, %s, resets %s; want rule %q, %s, %s, resets %s",
+					r.Rule, r.Kind, r.Confidence, r.ResetAt, tt.rule, tt.kind, tt.conf, tt.reset)
+			}
+		})
+	}
+
+	if p.HasError("codex", "Usage cap hit") {
+		t.Error("a claude rule matched a codex worker")
+	}
+	if !p.HasError("", "Usage cap hit") {
+		t.Error("an empty provider should try every rule")
+	}
+}
+
+func TestNewParserErrors(t *testing.T) {
+	for _, tt := range []struct {
+		rule Rule
+		want string
+	}{
+		{Rule{Name: "x", Match: `(`}, "rule x: match"},
+		{Rule{Name: "x", Match: `a`, Reset: `(`}, "rule x: reset"},
+		{Rule{Name: "x"}, "match is empty"},
+		{Rule{Name: "x", Match: `a`, Kind: "daily"}, "unknown kind"},
+	} {
+		if _, err := NewParser([]Rule{tt.rule}); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("NewParser(%+v) error = %v, want %q", tt.rule, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package usagelimit
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultStatusLines is how many non-blank lines at the bottom of a pane are
+// searched for usage-limit messages unless WithStatusLines says otherwise.
+const DefaultStatusLines = 12
+
+// quoted matches the lines each CLI renders as content rather than status:
+// echoed input, numbered file views and diffs, and boxed tool output. A
+// limit message quoted there is something the agent is reading or writing.
+var quoted = map[string]*regexp.Regexp{
+	"claude": regexp.MustCompile(`^\s*(?:>\s|⎿?\s*\d+\s*(?:→|[│|]|\s[-+ ]\s))`),
+	"gemini": regexp.MustCompile(`^\s*(?:>\s|│.*│\s*$|\d+\s*[│|])`),
+	"codex":  regexp.MustCompile(`^\s*(?:[›>]\s|[│└]\s|\d+\s*[│|])`),
+	"":       regexp.MustCompile(`^\s*(?:>\s|\d+\s*(?:→|[│|]))`),
+}
+
+// WithStatusLines returns a copy of p that searches the bottom n non-blank
+// lines of a screen.
+func (p *Parser) WithStatusLines(n int) *Parser {
+	c := *p
+	c.statusLines = n
+	return &c
+}
+
+// Screen returns the part of a captured pane where provider's CLI prints its
+// own messages: the bottom non-blank lines, with the lines above them and
+// those it renders as quoted content blanked out. Faint text counts as quoted,
+// which needs the styling of capture-pane -e; plain captures work, without
+// that check. The result is plain text for HasError and Parse, line for line
+// with captured, so Result.Row is a row of the pane.
+func (p *Parser) Screen(provider, captured string) string {
+	n := p.statusLines
+	if n <= 0 {
+		n = DefaultStatusLines
+	}
+	frame, ok := quoted[provider]
+	if !ok {
+		frame = quoted[""]
+	}
+	lines := styledLines(captured)
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1].text) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	start := len(lines)
+	for kept := 0; start > 0 && kept < n; start-- {
+		if strings.TrimSpace(lines[start-1].text) != "" {
+			kept++
+		}
+	}
+	out := make([]string, 0, len(lines))
+	for i, l := range lines {
+		if i < start || l.faint || frame.MatchString(l.text) {
+			out = append(out, "")
+			continue
+		}
+		out = append(out, l.text)
+	}
+	return strings.Join(out, "\n")
+}
+
+// Strip removes terminal escape sequences from a capture-pane -e screen.
+func Strip(captured string) string {
+	lines := styledLines(captured)
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		out[i] = l.text
+	}
+	return strings.Join(out, "\n")
+}
+
+// styledLine is one screen line without its escape sequences. faint is set
+// if all of its letters and digits are rendered faint (SGR 2); the frame
+// glyphs around them often are not.
+type styledLine struct {
+	text  string
+	faint bool
+}
+
+// styledLines splits a captured screen into lines, following the SGR faint
+// attribute across them as a terminal would.
+func styledLines(captured string) []styledLine {
+	var lines []styledLine
+	var b strings.Builder
+	faint := false
+	visible, dim := 0, 0
+	flush := func() {
+		lines = append(lines, styledLine{text: b.String(), faint: visible > 0 && dim == visible})
+		b.Reset()
+		visible, dim = 0, 0
+	}
+	for i := 0; i < len(captured); i++ {
+		c := captured[i]
+		switch {
+		case c == '\n':
+			flush()
+		case c == 0x1b && i+1 < len(captured) && captured[i+1] == '[':
+			j := i + 2
+			for j < len(captured) && (captured[j] < 0x40 || captured[j] > 0x7e) {
+				j++
+			}
+			if j < len(captured) && captured[j] == 'm' {
+				faint = sgrFaint(captured[i+2:j], faint)
+			}
+			i = j
+		case c == 0x1b && i+1 < len(captured) && captured[i+1] == ']':
+			// OSC (hyperlinks, titles) runs to BEL or ST.
+			j := i + 2
+			for j < len(captured) && captured[j] != 0x07 && !(captured[j] == 0x1b && j+1 < len(captured) && captured[j+1] == '\\') {
+				j++
+			}
+			if j < len(captured) && captured[j] == 0x1b {
+				j++
+			}
+			i = j
+		case c == 0x1b:
+			i++ // two-byte escape
+		default:
+			b.WriteByte(c)
+			if isAlnum(c) {
+				visible++
+				if faint {
+					dim++
+				}
+			}
+		}
+	}
+	flush()
+	return lines
+}
+
+// sgrFaint applies the parameters of an SGR sequence to the faint attribute.
+// Colour arguments (38;5;n, 38;2;r;g;b and the 48 and 58 forms) are skipped
+// so their numbers are not read as attributes.
+func sgrFaint(params string, faint bool) bool {
+	if params == "" {
+		return false
+	}
+	p := strings.FieldsFunc(params, func(r rune) bool { return r == ';' || r == ':' })
+	for i := 0; i < len(p); i++ {
+		n, err := strconv.Atoi(p[i])
+		if err != nil {
+			continue
+		}
+		switch n {
+		case 0, 22:
+			faint = false
+		case 2:
+			faint = true
+		case 38, 48, 58:
+			if i+1 < len(p) && p[i+1] == "5" {
+				i += 2
+			} else if i+1 < len(p) && p[i+1] == "2" {
+				i += 4
+			}
+		}
+	}
+	return faint
+}
+
+func isAlnum(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,104 @@
+package usagelimit
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// prompt is the bottom of an idle Claude screen.
+const prompt = "╭──────────────────────────╮\n│ >                        │\n╰──────────────────────────╯\n  ? for shortcuts\n"
+
+func TestScreenFalsePositives(t *testing.T) {
+	tests := []struct {
+		name, provider, screen string
+	}{
+		{"test file printed by a tool", "claude",
+			"● Bash(cat internal/usagelimit/parser_test.go)\n" +
+				"  ⎿  \x1b[2m{\"You have exceeded your usage limit for today.\", true},\x1b[22m\n" +
+				"     \x1b[2m{\"Claude usage limit reached. Your limit will reset at 5pm\", true},\x1b[0m\n" + prompt},
+		{"edit diff of the parser test", "claude",
+			"● Update(internal/usagelimit/parser_test.go)\n" +
+				"  ⎿  Updated internal/usagelimit/parser_test.go with 1 addition\n" +
+				"      31    tests := []struct {\n" +
+				"      32 +    {\"You've hit your limit · resets 5pm\", true},\n" + prompt},
+		{"file view with line numbers", "claude",
+			"  ⎿       12→API usage limits — try again after 15:00 UTC\n" + prompt},
+		{"log file in a gemini tool box", "gemini",
+			"│ ✓ ReadFile swarm.log                                  │\n" +
+				"│ 2026-10-16 worker-2 Quota exceeded for quota metric   │\n" +
+				"> Type your message or @path/to/file\n"},
+		{"codex command output", "codex",
+			"• Ran tail -n 1 daemon.log\n  └ [worker-1] API usage limit hit: \"You've hit your usage limit. Try again in 4h.\"\n" +
+				"▌ Ask Codex to do anything\n ⏎ send\n"},
+		{"user input echoed back", "claude",
+			"> why does the daemon say \"Claude usage limit reached\"?\n" + prompt},
+		{"message scrolled above the status lines", "claude",
+			"Claude usage limit reached. Your limit will reset at 5pm.\n" +
+				strings.Repeat("● Working on it\n", 20) + prompt},
+	}
+	now := time.Date(2026, 10, 16, 14, 7, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Default()
+			screen := p.Screen(tt.provider, tt.screen)
+			if p.HasError(tt.provider, screen) {
+				t.Errorf("HasError found a limit in %q", screen)
+			}
+			if r := p.Parse(tt.provider, screen, now); r.Found {
+				t.Errorf("Parse matched %q with rule %s", r.Matched, r.Rule)
+			}
+		})
+	}
+}
+
+func TestScreenKeepsLimitMessages(t *testing.T) {
+	tests := []struct {
+		name, provider, screen, rule string
+		row                          int
+	}{
+		{"claude red error", "claude",
+			"\x1b[31m  ⎿  5-hour limit reached ∙ resets 5pm (America/New_York)\x1b[39m\n" + prompt, "claude-session", 0},
+		{"truecolor is not faint", "claude",
+			"\x1b[38;2;255;2;2m● You've hit your limit · resets 5:30pm (Europe/Paris)\x1b[0m\n" + prompt, "hit-your-limit", 0},
+		{"after faint tool output", "claude",
+			"  ⎿  \x1b[2mok\x1b[22m\nClaude usage limit reached. Your limit will reset at 7pm.\n" + prompt, "claude-usage", 1},
+		{"plain capture", "codex",
+			"■ You've hit your usage limit. Try again in 4h 32m.\n▌ Ask Codex to do anything\n", "hit-your-limit", 0},
+		{"gemini error", "gemini",
+			"✕ [API Error: Quota exceeded for quota metric 'Gemini 2.5 Pro Requests']\n> Type your message\n", "gemini-quota-metric", 0},
+		{"row counts the lines above the status lines", "",
+			strings.Repeat("output\n", 20) + "You have exceeded your usage limit.\n" + prompt, "exceeded-usage", 20},
+	}
+	now := time.Date(2026, 10, 16, 14, 7, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Default()
+			r := p.Parse(tt.provider, p.Screen(tt.provider, tt.screen), now)
+			if !r.Found || r.Rule != tt.rule {
+				t.Errorf("Parse = %q (found %v), want rule %s", r.Rule, r.Found, tt.rule)
+			}
+			if r.Row != tt.row {
+				t.Errorf("Row = %d, want %d", r.Row, tt.row)
+			}
+		})
+	}
+}
+
+func TestWithStatusLines(t *testing.T) {
+	screen := "You have exceeded your usage limit.\n" + strings.Repeat("line\n", 5)
+	if Default().WithStatusLines(5).HasError("", Default().WithStatusLines(5).Screen("", screen)) {
+		t.Error("5 status lines: found a message 6 lines up")
+	}
+	p := Default().WithStatusLines(6)
+	if !p.HasError("", p.Screen("", screen)) {
+		t.Error("6 status lines: missed a message 6 lines up")
+	}
+}
+
+func TestStrip(t *testing.T) {
+	in := "\x1b[1m\x1b[38;5;208mbold\x1b[0m \x1b]8;;https://x\x1b\\link\x1b]8;;\x1b\\\n\x1b[2mdim\x1b[m"
+	if got, want := Strip(in), "bold link\ndim"; got != want {
+		t.Errorf("Strip() = %q, want %q", got, want)
+	}
+}